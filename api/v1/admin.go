@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+)
+
+func RegisterAdminRoutes(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
+
+	protected := router.Group("/admin")
+	protected.Use(mw.JWTAuthMiddleware(), middleware.RequireRole(model.RoleAdmin))
+	{
+		protected.GET("/users", middleware.RequirePermission(model.PermissionUserManage), h.ListUsersHandler)
+		protected.POST("/users/:userId/suspend", middleware.RequirePermission(model.PermissionUserManage), h.SuspendUserHandler)
+		protected.POST("/users/:userId/unsuspend", middleware.RequirePermission(model.PermissionUserManage), h.UnsuspendUserHandler)
+		protected.DELETE("/users/:userId", middleware.RequirePermission(model.PermissionUserManage), h.DeleteUserHandler)
+		protected.DELETE("/products/:productId", middleware.RequirePermission(model.PermissionContentModerate), h.TakeDownProductHandler)
+		protected.DELETE("/files/:fileId", middleware.RequirePermission(model.PermissionContentModerate), h.TakeDownFileHandler)
+	}
+}
@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+)
+
+func RegisterAuthRoutes(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
+	router.POST("/auth/refresh", h.RefreshTokenHandler)
+
+	protected := router.Group("/logout")
+	protected.Use(mw.JWTAuthMiddleware())
+	{
+		protected.POST("", h.LogoutHandler)
+		protected.POST("/all", h.LogoutAllHandler)
+	}
+}
@@ -6,13 +6,17 @@ import (
 	"sprint3/internal/middleware"
 )
 
-func RegisterFileRoutes(router *gin.RouterGroup) {
+func RegisterFileRoutes(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
 
 	protected := router.Group("file")
-	protected.Use(middleware.JWTAuthMiddleware())
+	protected.Use(mw.JWTAuthMiddleware())
 	{
-		protected.POST("/", handler.UploadFileHandler)
-
+		protected.POST("/", h.UploadFileHandler)
+		protected.POST("/presign", h.PresignUploadHandler)
+		protected.POST("/complete", h.CompleteUploadHandler)
+		protected.GET("", h.ListFilesHandler)
+		protected.GET("/:fileId", h.GetFileHandler)
+		protected.DELETE("/:fileId", h.DeleteFileHandler)
 	}
 
 }
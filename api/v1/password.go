@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/handler"
+)
+
+func RegisterPasswordRoutes(router *gin.RouterGroup, h *handler.Handler) {
+	router.POST("/password/forgot", h.ForgotPasswordHandler)
+	router.POST("/password/reset", h.ResetPasswordHandler)
+}
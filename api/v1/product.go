@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+)
+
+func RegisterProductRoutes(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
+
+	router.GET("/product", h.GetProductsHandler)
+
+	protected := router.Group("/product")
+	protected.Use(mw.JWTAuthMiddleware(), middleware.RequirePermission(model.PermissionProductWrite))
+	{
+		protected.POST("", mw.RequireVerifiedSeller(), h.CreateProductHandler)
+		protected.PUT("/:productId", mw.RequireVerifiedSeller(), h.UpdateProductHandler)
+		protected.DELETE("/:productId", h.DeleteProductHandler)
+	}
+}
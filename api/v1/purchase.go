@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+)
+
+func RegisterPurchaseRoutes(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
+
+	protected := router.Group("/purchase")
+	protected.Use(mw.JWTAuthMiddleware())
+	{
+		protected.POST("", middleware.RequirePermission(model.PermissionPurchaseCreate), mw.RequireVerifiedBuyer(),
+			h.CreatePurchaseHandler)
+		protected.POST("/:purchaseId", h.PayPurchaseHandler)
+		protected.POST("/:purchaseId/cancel", h.CancelPurchaseHandler)
+	}
+}
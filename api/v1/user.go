@@ -6,22 +6,22 @@ import (
 	"sprint3/internal/middleware"
 )
 
-func RegisterUserRouter(router *gin.RouterGroup) {
+func RegisterUserRouter(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
 
 	{
-		router.POST("/register/email", handler.RegisterUserEmail)
-		router.POST("/register/phone", handler.RegisterUserPhone)
-		router.POST("/login/email", handler.LoginUserEmail)
-		router.POST("/login/phone", handler.LoginUserPhone)
+		router.POST("/register/email", h.RegisterUserEmail)
+		router.POST("/register/phone", h.RegisterUserPhone)
+		router.POST("/login/email", h.LoginUserEmail)
+		router.POST("/login/phone", h.LoginUserPhone)
 	}
 	//Kegunaan Protected itu ntar buat kalau mau akses itu harus login
 	protected := router.Group("/user")
-	protected.Use(middleware.JWTAuthMiddleware())
+	protected.Use(mw.JWTAuthMiddleware())
 	{
-		/*
-				USE EXAMPLE
-			protected.GET("/", handler.GetUserProfileHandler)
-		*/
-
+		protected.GET("", h.GetUserProfileHandler)
+		protected.PATCH("", h.UpdateUserProfileHandler)
+		protected.PATCH("/password", h.ChangePasswordHandler)
+		protected.POST("/link/phone", h.LinkPhoneHandler)
+		protected.POST("/link/email", h.LinkEmailHandler)
 	}
 }
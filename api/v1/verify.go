@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+)
+
+func RegisterVerifyRoutes(router *gin.RouterGroup, h *handler.Handler, mw *middleware.Middleware) {
+
+	protected := router.Group("/verify")
+	protected.Use(mw.JWTAuthMiddleware())
+	{
+		protected.POST("/email/send", h.SendEmailVerificationHandler)
+		protected.POST("/email", h.VerifyEmailHandler)
+		protected.POST("/phone/send", h.SendPhoneVerificationHandler)
+		protected.POST("/phone", h.VerifyPhoneHandler)
+	}
+}
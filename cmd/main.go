@@ -1,59 +1,37 @@
 package main
 
 import (
-	"fmt"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/gin-gonic/gin"
+	"context"
 	"log"
 	"os"
-	v1 "sprint3/api/v1"
+	"os/signal"
+	"sprint3/internal/app"
+	"sprint3/pkg/config"
 	"sprint3/pkg/database"
+	"syscall"
 )
 
 func main() {
-	database.InitDB()
-	defer database.CloseDB()
+	cfg := config.LoadEnv()
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:   aws.String("ap-southeast-2"),
-		LogLevel: aws.LogLevel(aws.LogDebugWithHTTPBody),
-	})
-
-	if err != nil {
-		log.Fatal("Error creating AWS session: ", err)
-	}
-
-	s3Client := s3.New(sess)
-
-	result, err := s3Client.ListBuckets(nil)
-	if err != nil {
-		fmt.Println("Error listing S3 buckets:", err)
+	// go run ./cmd migrate up | down [steps] | status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.RunMigrateCommand(context.Background(), cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migrate failed: %v", err)
+		}
 		return
 	}
 
-	fmt.Println("Buckets:")
-	for _, bucket := range result.Buckets {
-		fmt.Printf(" - %s\n", *bucket.Name)
-	}
+	// ctx dibatalkan saat SIGINT/SIGTERM, App.Run lalu menutup semua resource sebelum kembali
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	router := gin.Default()
-
-	v1Group := router.Group("/v1")
-	{
-		//v1.RegisterActivityRoutes(v1Group, handlerInstance)
-		v1.RegisterUserRouter(v1Group)
-		v1.RegisterFileRoutes(v1Group)
-	}
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8081"
-	}
-	log.Printf("Server started on http://localhost:%s", port)
-	err = router.Run(":" + port)
+	application, err := app.New(ctx, cfg, app.Options{})
 	if err != nil {
-		return
+		log.Fatalf("Failed to start application: %v", err)
+	}
+	if err := application.Run(ctx); err != nil {
+		log.Fatalf("Server stopped: %v", err)
 	}
+	log.Println("Server stopped")
 }
@@ -0,0 +1,294 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log"
+	"net/http"
+	v1 "sprint3/api/v1"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/service"
+	"sprint3/internal/storage"
+	"sprint3/pkg/config"
+	"sprint3/pkg/database"
+	"sync"
+	"time"
+)
+
+// Backoff koneksi database saat start, naik 2x setiap gagal sampai maksimum
+const (
+	startupRetryInitialBackoff = 500 * time.Millisecond
+	startupRetryMaxBackoff     = 10 * time.Second
+)
+
+// App satu instance aplikasi beserta semua dependensinya. Tidak ada state di level package,
+// jadi beberapa App dengan config berbeda bisa berjalan bersamaan, misal satu per test.
+type App struct {
+	Config   *config.Config
+	DB       *pgxpool.Pool
+	Storage  *storage.Client
+	Notifier notifier.Notifier
+	Tokens   *middleware.TokenSigner
+	Service  *service.Service
+	Router   *gin.Engine
+	Server   *http.Server
+
+	// jobs background job yang harus ditunggu selesai sebelum database ditutup
+	jobs sync.WaitGroup
+	// ownsDB dan ownsStorage true kalau resource dibuat oleh New, hanya itu yang ditutup Close
+	ownsDB      bool
+	ownsStorage bool
+}
+
+// Options dependensi yang bisa diberikan ke New, misal repository dan storage di memori untuk test.
+// Field yang kosong dibuat dari cfg. Repositories tanpa DB berarti App berjalan tanpa database:
+// route product, purchase dan admin dibalas 503 dan background job tidak dijalankan.
+type Options struct {
+	DB           *pgxpool.Pool
+	Repositories *repository.Repositories
+	Storage      *storage.Client
+	Notifier     notifier.Notifier
+}
+
+// New membuat App dari cfg dan opts: koneksi database (sekaligus migrasi kalau DB_AUTO_MIGRATE aktif),
+// storage, notifier dan key JWT, lalu mendaftarkan semua route. Pemanggil wajib memanggil Close.
+func New(ctx context.Context, cfg *config.Config, opts Options) (*App, error) {
+	a := &App{Config: cfg, DB: opts.DB, Storage: opts.Storage, Notifier: opts.Notifier}
+	if opts.DB == nil && opts.Repositories == nil {
+		db, err := openDatabase(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.DB, a.ownsDB = db, true
+	}
+
+	repos := opts.Repositories
+	if repos == nil {
+		postgres := repository.NewPostgres(a.DB)
+		repos = &postgres
+	}
+	if err := a.init(*repos); err != nil {
+		a.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *App) init(repos repository.Repositories) error {
+	var err error
+	if a.Storage == nil {
+		a.Storage, err = storage.NewClient(a.Config)
+		if err != nil {
+			return err
+		}
+		a.ownsStorage = true
+		log.Printf("✅ Storage initialized with driver %s", a.Config.StorageDriver)
+	}
+
+	// Storage yang belum bisa dihubungi tidak menggagalkan start, hanya endpoint file yang terdampak
+	// dan /readyz melaporkannya sampai storage kembali
+	pingCtx, cancel := context.WithTimeout(context.Background(), a.Config.ReadinessTimeout)
+	defer cancel()
+	if err := a.Storage.Ping(pingCtx); err != nil {
+		log.Printf("⚠️  WARNING: storage is not reachable yet: %v", err)
+	}
+
+	if a.Notifier == nil {
+		a.Notifier, err = notifier.New(a.Config)
+		if err != nil {
+			return err
+		}
+		if a.Config.IsProduction() && a.Config.NotifierDriver != notifier.DriverRemote {
+			log.Printf("⚠️  WARNING: notifier driver %s does not deliver messages to users", a.Config.NotifierDriver)
+		}
+		log.Printf("✅ Notifier initialized with driver %s", a.Config.NotifierDriver)
+	}
+
+	a.Tokens, err = middleware.NewTokenSigner(a.Config)
+	if err != nil {
+		return err
+	}
+
+	a.Service = service.New(a.Config, a.DB, repos, a.Storage, a.Notifier)
+	a.Router = gin.Default()
+	a.registerRoutes(handler.New(a.Config, a.Service, a.Tokens, a.Storage), middleware.New(a.Config, a.Tokens, a.Service))
+
+	a.Server = &http.Server{
+		Addr:         ":" + a.Config.Port,
+		Handler:      a.Router,
+		ReadTimeout:  a.Config.HTTPReadTimeout,
+		WriteTimeout: a.Config.HTTPWriteTimeout,
+		IdleTimeout:  a.Config.HTTPIdleTimeout,
+	}
+	return nil
+}
+
+// openDatabase mencoba database.Open dengan backoff selama StartupRetryTimeout, supaya aplikasi
+// yang start bersamaan dengan database (misal lewat docker compose) tidak langsung keluar
+func openDatabase(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	deadline := time.Now().Add(cfg.StartupRetryTimeout)
+	backoff := startupRetryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		db, err := database.Open(ctx, cfg)
+		if err == nil {
+			return db, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+		log.Printf("Database not ready (attempt %d): %v, retrying in %s", attempt, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > startupRetryMaxBackoff {
+			backoff = startupRetryMaxBackoff
+		}
+	}
+}
+
+func (a *App) registerRoutes(h *handler.Handler, mw *middleware.Middleware) {
+	a.Router.GET("/healthz", h.HealthzHandler)
+	a.Router.GET("/readyz", h.ReadyzHandler)
+
+	// Local/memory storage tidak punya server sendiri, jadi file dan upload langsung dilayani oleh router
+	if _, ok := a.Storage.Storage.(storage.SignedURLVerifier); ok {
+		a.Router.GET(storage.LocalServePath+"/*key", h.ServeObjectHandler)
+		a.Router.PUT(storage.LocalServePath+"/*key", h.PutObjectHandler)
+	}
+
+	a.Router.GET("/.well-known/jwks.json", h.JWKSHandler)
+
+	v1Group := a.Router.Group("/v1")
+	{
+		v1.RegisterUserRouter(v1Group, h, mw)
+		v1.RegisterAuthRoutes(v1Group, h, mw)
+		v1.RegisterPasswordRoutes(v1Group, h)
+		v1.RegisterVerifyRoutes(v1Group, h, mw)
+		v1.RegisterFileRoutes(v1Group, h, mw)
+
+		// Product, purchase dan admin langsung memakai database, tanpa DB dibalas 503 sebelum handler jalan
+		dbGroup := v1Group
+		if a.DB == nil {
+			dbGroup = v1Group.Group("", databaseUnavailable)
+		}
+		v1.RegisterProductRoutes(dbGroup, h, mw)
+		v1.RegisterPurchaseRoutes(dbGroup, h, mw)
+		v1.RegisterAdminRoutes(dbGroup, h, mw)
+	}
+}
+
+func databaseUnavailable(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Service unavailable without a database"})
+}
+
+// Run menjalankan HTTP server dan background job sampai ctx dibatalkan (SIGINT/SIGTERM dari main)
+// atau server gagal, lalu berhenti berurutan: server berhenti menerima koneksi baru dan menunggu
+// request yang sedang berjalan, background job dihentikan dan ditunggu, terakhir database dan storage
+// ditutup. Menunggu request dan job dibatasi ShutdownTimeout.
+func (a *App) Run(ctx context.Context) error {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	a.startJobs(jobsCtx)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server started on http://localhost%s", a.Server.Addr)
+		serverErr <- a.Server.ListenAndServe()
+	}()
+
+	var runErr error
+	select {
+	case err := <-serverErr:
+		// Misal port sudah dipakai, resource lain tetap ditutup dengan benar
+		runErr = err
+	case <-ctx.Done():
+		log.Println("🛑 Shutdown signal received, draining in-flight requests")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.Config.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.Server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server did not drain in time: %v", err)
+	}
+
+	stopJobs()
+	if err := a.waitJobs(shutdownCtx); err != nil {
+		log.Printf("Background jobs did not stop in time: %v", err)
+	}
+
+	a.Close()
+	if errors.Is(runErr, http.ErrServerClosed) {
+		return nil
+	}
+	return runErr
+}
+
+// startJobs menjalankan background job sampai ctx dibatalkan. Sweeper dan expirer langsung memakai
+// database, jadi tidak dijalankan kalau App dibuat tanpa DB.
+func (a *App) startJobs(ctx context.Context) {
+	// Pruner hanya memakai repository, jadi tetap jalan tanpa database
+	a.jobs.Add(1)
+	go func() {
+		defer a.jobs.Done()
+		a.Service.RunLoginAttemptPruner(ctx, a.Config.LoginAttemptPruneInterval)
+	}()
+
+	if a.DB == nil {
+		log.Println("⚠️  WARNING: no database configured, orphan sweeper and purchase expirer are disabled")
+		return
+	}
+
+	a.jobs.Add(1)
+	go func() {
+		defer a.jobs.Done()
+		a.Service.RunOrphanSweeper(ctx, a.Config.OrphanSweepInterval, a.Config.OrphanGracePeriod)
+	}()
+
+	a.jobs.Add(1)
+	go func() {
+		defer a.jobs.Done()
+		a.Service.RunPurchaseExpirer(ctx, a.Config.PurchaseExpiryInterval)
+	}()
+}
+
+// waitJobs menunggu background job dan pekerjaan background Service (misal pengiriman token reset)
+func (a *App) waitJobs(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.jobs.Wait()
+		a.Service.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close menutup database lalu storage yang dibuat oleh New, resource dari Options tetap milik pemanggil.
+// Dipanggil Run saat berhenti, atau langsung kalau App tidak pernah dijalankan (misal di test).
+func (a *App) Close() {
+	if a.ownsDB && a.DB != nil {
+		a.DB.Close()
+		log.Println("🛑 Database connection closed")
+	}
+	if a.ownsStorage && a.Storage != nil {
+		if err := a.Storage.Close(); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		} else {
+			log.Println("🛑 Storage client closed")
+		}
+	}
+}
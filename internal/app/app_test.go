@@ -0,0 +1,115 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"sprint3/internal/app"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/testutil"
+	"testing"
+)
+
+// startApp menjalankan App dengan repository dan storage di memori di belakang httptest.Server
+func startApp(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := testutil.Config()
+	repos := repository.NewMemory()
+	a, err := app.New(context.Background(), cfg, app.Options{
+		Repositories: &repos,
+		Storage:      testutil.MemoryStorage(cfg),
+		Notifier:     notifier.LogNotifier{},
+	})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	server := httptest.NewServer(a.Router)
+	t.Cleanup(func() {
+		server.Close()
+		a.Close()
+	})
+	return server
+}
+
+func call(t *testing.T, server *httptest.Server, method, path, token string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	var decoded map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&decoded)
+	return res.StatusCode, decoded
+}
+
+func TestInstancesAreIsolated(t *testing.T) {
+	first, second := startApp(t), startApp(t)
+
+	for _, server := range []*httptest.Server{first, second} {
+		if status, body := call(t, server, http.MethodGet, "/readyz", "", nil); status != http.StatusOK {
+			t.Fatalf("readyz = %d %v, want 200", status, body)
+		}
+	}
+
+	// Email yang sama bisa didaftarkan di kedua instance karena repository tidak dibagi
+	account := gin.H{"email": "buyer@example.com", "password": "password123"}
+	status, body := call(t, first, http.MethodPost, "/v1/register/email", "", account)
+	if status != http.StatusOK {
+		t.Fatalf("register on first = %d %v", status, body)
+	}
+	firstToken := body["token"].(string)
+
+	status, body = call(t, second, http.MethodPost, "/v1/login/email", "", account)
+	if status != http.StatusNotFound {
+		t.Fatalf("login on second before register = %d %v, want 404", status, body)
+	}
+	status, body = call(t, second, http.MethodPost, "/v1/register/email", "", account)
+	if status != http.StatusOK {
+		t.Fatalf("register on second = %d %v", status, body)
+	}
+	secondToken := body["token"].(string)
+
+	// Setiap instance punya key JWT sendiri, token instance lain ditolak
+	if status, _ := call(t, second, http.MethodGet, "/v1/user", firstToken, nil); status != http.StatusUnauthorized {
+		t.Fatalf("first token on second = %d, want 401", status)
+	}
+	if status, _ := call(t, first, http.MethodGet, "/v1/user", secondToken, nil); status != http.StatusUnauthorized {
+		t.Fatalf("second token on first = %d, want 401", status)
+	}
+	if status, body := call(t, first, http.MethodGet, "/v1/user", firstToken, nil); status != http.StatusOK || body["email"] != "buyer@example.com" {
+		t.Fatalf("first token on first = %d %v, want buyer@example.com", status, body)
+	}
+
+	// Tanpa database route product, purchase dan admin dibalas 503, bukan panic
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/v1/product"},
+		{http.MethodPost, "/v1/product"},
+		{http.MethodPost, "/v1/purchase"},
+		{http.MethodGet, "/v1/admin/users"},
+	}
+	for _, route := range routes {
+		if status, body := call(t, first, route.method, route.path, firstToken, nil); status != http.StatusServiceUnavailable {
+			t.Fatalf("%s %s without database = %d %v, want 503", route.method, route.path, status, body)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sprint3/internal/middleware"
+	"sprint3/internal/service"
+	"strconv"
+)
+
+const (
+	defaultUserLimit = 20
+	maxUserLimit     = 100
+)
+
+// ListUsersHandler daftar user untuk admin, query param yang tidak valid diabaikan
+func (h *Handler) ListUsersHandler(c *gin.Context) {
+	limit, offset := defaultUserLimit, 0
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= maxUserLimit {
+		limit = l
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	users, err := h.svc.ListUsers(limit, offset)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *Handler) SuspendUserHandler(c *gin.Context) {
+	h.setUserSuspended(c, true)
+}
+
+func (h *Handler) UnsuspendUserHandler(c *gin.Context) {
+	h.setUserSuspended(c, false)
+}
+
+func (h *Handler) setUserSuspended(c *gin.Context, suspended bool) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	adminID := middleware.MustPrincipal(c).UserID
+
+	if err := h.svc.SetUserSuspended(adminID, userID, suspended, c.ClientIP()); err != nil {
+		handleAdminError(c, err)
+		return
+	}
+
+	log.Printf("User suspended = %t: ID = %d by admin ID = %d", suspended, userID, adminID)
+	c.JSON(http.StatusOK, gin.H{"userId": userID, "suspended": suspended})
+}
+
+func (h *Handler) DeleteUserHandler(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	adminID := middleware.MustPrincipal(c).UserID
+
+	if err := h.svc.DeleteUser(adminID, userID, c.ClientIP()); err != nil {
+		handleAdminError(c, err)
+		return
+	}
+
+	log.Printf("User deleted: ID = %d by admin ID = %d", userID, adminID)
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+func (h *Handler) TakeDownProductHandler(c *gin.Context) {
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+	adminID := middleware.MustPrincipal(c).UserID
+
+	if err := h.svc.TakeDownProduct(adminID, productID, c.ClientIP()); err != nil {
+		handleAdminError(c, err)
+		return
+	}
+
+	log.Printf("Product taken down: ID = %d by admin ID = %d", productID, adminID)
+	c.JSON(http.StatusOK, gin.H{"message": "Product taken down"})
+}
+
+func (h *Handler) TakeDownFileHandler(c *gin.Context) {
+	fileID, ok := parseFileID(c)
+	if !ok {
+		return
+	}
+	adminID := middleware.MustPrincipal(c).UserID
+
+	if err := h.svc.TakeDownFile(adminID, fileID, c.ClientIP()); err != nil {
+		handleAdminError(c, err)
+		return
+	}
+
+	log.Printf("File taken down: ID = %d by admin ID = %d", fileID, adminID)
+	c.JSON(http.StatusOK, gin.H{"message": "File taken down"})
+}
+
+func parseUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func handleAdminError(c *gin.Context, err error) {
+	log.Printf("Service error: %v", err)
+	if errors.Is(err, service.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	} else if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	} else if errors.Is(err, service.ErrFileNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	} else if errors.Is(err, service.ErrCannotModerateSelf) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admin cannot suspend or delete own account"})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// RefreshTokenHandler menukar refresh token dengan access token dan refresh token baru
+func (h *Handler) RefreshTokenHandler(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, session, err := h.svc.RotateRefreshToken(req.RefreshToken)
+	if err != nil {
+		log.Printf("Refresh error: %v", err)
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		} else if errors.Is(err, service.ErrUserSuspended) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	token, err := h.tokens.GenerateToken(user, session.ID)
+	if err != nil {
+		log.Printf("Token generation failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"email":        stringOrEmpty(user.Email),
+		"phone":        stringOrEmpty(user.Phone),
+		"token":        token,
+		"refreshToken": session.RefreshToken,
+		"expiresIn":    int(h.tokens.AccessTokenTTL().Seconds()),
+	})
+}
+
+// LogoutHandler mencabut session dan access token yang sedang dipakai
+func (h *Handler) LogoutHandler(c *gin.Context) {
+	principal := middleware.MustPrincipal(c)
+	err := h.svc.RevokeSession(principal.UserID, principal.SessionID, principal.TokenID, principal.ExpiresAt)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAllHandler mencabut semua session user di semua device
+func (h *Handler) LogoutAllHandler(c *gin.Context) {
+	revoked, err := h.svc.RevokeAllSessions(middleware.MustPrincipal(c).UserID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out from all sessions", "revokedSessions": revoked})
+}
+
+// issueTokens membuat session baru untuk user yang baru login/register
+func (h *Handler) issueTokens(user *model.User) (token, refreshToken string, err error) {
+	session, err := h.svc.CreateSession(user.Id)
+	if err != nil {
+		return "", "", err
+	}
+	token, err = h.tokens.GenerateToken(user, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	return token, session.RefreshToken, nil
+}
+
+// JWKSHandler public key untuk verifikasi access token oleh service lain
+func (h *Handler) JWKSHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.tokens.JWKS())
+}
@@ -1,68 +1,232 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"io"
+	"log"
 	"net/http"
 	"path/filepath"
-	"sprint3/internal/awsr"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
 	"sprint3/internal/service"
+	"sprint3/internal/storage"
 	"strconv"
-	"strings"
+	"time"
 )
 
-func UploadFileHandler(c *gin.Context) {
+const (
+	maxUploadFileSize = 1024 * 100
+	// Batas body request: ukuran file ditambah ruang untuk header multipart
+	maxUploadRequestSize = maxUploadFileSize + 1024*10
+
+	defaultFileLimit = 10
+	maxFileLimit     = 100
+)
+
+func (h *Handler) UploadFileHandler(c *gin.Context) {
+	// Batasi body supaya file besar tidak sempat dibaca seluruhnya
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadRequestSize)
+
 	// Mengambil file dari form-data
 	file, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File not found"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File not found or exceeds 100KiB"})
 		return
 	}
+	// Hapus file sementara yang mungkin dibuat saat parsing multipart
+	defer func() {
+		if c.Request.MultipartForm != nil {
+			if err := c.Request.MultipartForm.RemoveAll(); err != nil {
+				log.Printf("Failed to remove multipart temp files: %v", err)
+			}
+		}
+	}()
 
-	// Memvalidasi ekstensi file
-	fileExtension := strings.ToLower(filepath.Ext(file.Filename))
-	if fileExtension != ".jpeg" && fileExtension != ".jpg" && fileExtension != ".png" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only jpeg, jpg, png allowed."})
+	visibility, ok := parseVisibility(c, c.PostForm("visibility"))
+	if !ok {
 		return
 	}
 
 	// Memvalidasi ukuran file (maksimum 100KB)
-	if file.Size > 1024*100 {
+	if file.Size > maxUploadFileSize {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "File size exceeds 100KiB"})
 		return
 	}
 
-	// Menyimpan file sementara secara lokal
-	uploadPath := filepath.Join("uploads", file.Filename)
-	if err := c.SaveUploadedFile(file, uploadPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file locally"})
+	// Membaca isi file sekali ke memory, dipakai untuk upload file asli dan thumbnail
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
 		return
 	}
+	defer src.Close()
 
-	// Upload ke S3
-	fileURL, err := awsr.UploadToS3(uploadPath)
+	data, err := io.ReadAll(io.LimitReader(src, maxUploadFileSize+1))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file to S3"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	if len(data) > maxUploadFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File size exceeds 100KiB"})
 		return
 	}
 
-	// Membuat thumbnail file
-	thumbnailURL, err := awsr.CreateThumbnailAndUploadToS3(uploadPath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create thumbnail"})
+	storedFile, ok := h.saveImage(c, data, filepath.Ext(file.Filename), visibility)
+	if !ok {
 		return
 	}
 
+	// Menyusun response
+	c.JSON(http.StatusOK, fileResponse(storedFile))
+}
+
+// saveImage memvalidasi, memproses, mengunggah dan mencatat gambar milik user yang login.
+// Kalau gagal, response error sudah dikirim dan ok bernilai false.
+func (h *Handler) saveImage(c *gin.Context, data []byte, fileExtension, visibility string) (*model.File, bool) {
+	// Memvalidasi tipe file dari isi file (magic bytes), bukan hanya dari ekstensi
+	imgType, err := h.storage.Image.DetectImageType(data, fileExtension)
+	if err != nil {
+		log.Printf("Image validation failed: %v", err)
+		if errors.Is(err, storage.ErrImageTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Image dimensions exceed 4096x4096"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only jpeg, jpg, png (and gif if enabled) allowed."})
+		}
+		return nil, false
+	}
+
+	// Membersihkan metadata, memperbaiki orientasi dan membuat semua ukuran thumbnail
+	processed, err := h.storage.Image.ProcessImage(data, imgType)
+	if err != nil {
+		log.Printf("Image processing failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process image"})
+		return nil, false
+	}
+
+	// Upload ke storage
+	uploaded, err := h.storage.UploadImage(processed, visibility == model.FileVisibilityPrivate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file to storage"})
+		return nil, false
+	}
+
 	// Menyimpan data file ke database
-	storedFile, err := service.AddFile(fileURL, thumbnailURL)
+	storedFile, err := h.svc.AddFile(&model.File{
+		UserId:       middleware.MustPrincipal(c).UserID,
+		URI:          uploaded.URI,
+		ThumbnailURI: uploaded.ThumbnailURI(),
+		Renditions:   uploaded.Renditions,
+		ObjectKeys:   uploaded.Keys,
+		Visibility:   visibility,
+	})
 	if err != nil {
+		// Object sudah terunggah tapi tidak tercatat, hapus supaya tidak jadi yatim di bucket
+		h.storage.DeleteObjects(uploaded.Keys)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file in the database"})
+		return nil, false
+	}
+	return storedFile, true
+}
+
+// ListFilesHandler daftar file milik user yang login, query param yang tidak valid diabaikan
+func (h *Handler) ListFilesHandler(c *gin.Context) {
+	limit, offset := defaultFileLimit, 0
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= maxFileLimit {
+		limit = l
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	files, err := h.svc.GetFiles(middleware.MustPrincipal(c).UserID, limit, offset)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	// Menyusun response
-	c.JSON(http.StatusOK, gin.H{
-		"fileId":           strconv.Itoa(storedFile.ID),
-		"fileUri":          storedFile.URI,
-		"fileThumbnailUri": storedFile.ThumbnailURI,
-	})
+	response := make([]gin.H, 0, len(files))
+	for i := range files {
+		response = append(response, fileResponse(&files[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetFileHandler(c *gin.Context) {
+	fileID, ok := parseFileID(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.svc.GetFile(fileID, middleware.MustPrincipal(c).UserID)
+	if err != nil {
+		handleFileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, fileResponse(file))
+}
+
+func (h *Handler) DeleteFileHandler(c *gin.Context) {
+	log.Println("Handler DeleteFileHandler hit")
+	fileID, ok := parseFileID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteFile(fileID, middleware.MustPrincipal(c).UserID); err != nil {
+		handleFileError(c, err)
+		return
+	}
+
+	log.Printf("File deleted: ID = %d", fileID)
+	c.JSON(http.StatusOK, gin.H{"message": "File deleted"})
+}
+
+// parseVisibility default public kalau kosong
+func parseVisibility(c *gin.Context, visibility string) (string, bool) {
+	switch visibility {
+	case "":
+		return model.FileVisibilityPublic, true
+	case model.FileVisibilityPublic, model.FileVisibilityPrivate:
+		return visibility, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Visibility must be public or private"})
+		return "", false
+	}
+}
+
+func parseFileID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("fileId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func handleFileError(c *gin.Context, err error) {
+	log.Printf("Service error: %v", err)
+	if errors.Is(err, service.ErrFileNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	} else if errors.Is(err, service.ErrFileInUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": "File is still used by a profile, product or purchase"})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func fileResponse(file *model.File) gin.H {
+	renditions := file.Renditions
+	if renditions == nil {
+		renditions = []model.FileRendition{}
+	}
+	return gin.H{
+		"fileId":           strconv.Itoa(file.ID),
+		"fileUri":          file.URI,
+		"fileThumbnailUri": file.ThumbnailURI,
+		"renditions":       renditions,
+		"visibility":       file.Visibility,
+		"createdAt":        file.CreatedAt.Format(time.RFC3339),
+	}
 }
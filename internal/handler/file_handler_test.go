@@ -0,0 +1,205 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sprint3/internal/storage"
+	"sprint3/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upload mengirim form-data ke POST /v1/file, visibility kosong tidak dikirim
+func (s *testServer) upload(token, filename string, data []byte, visibility string) (int, map[string]interface{}) {
+	s.t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		s.t.Fatalf("create form file: %v", err)
+	}
+	part.Write(data)
+	if visibility != "" {
+		form.WriteField("visibility", visibility)
+	}
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/file/", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &decoded)
+	return rec.Code, decoded
+}
+
+// fetch membaca object lewat route /files dari URL lengkap yang dikembalikan API
+func (s *testServer) fetch(rawURL string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		s.t.Fatalf("parse %s: %v", rawURL, err)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, parsed.RequestURI(), nil))
+	return rec
+}
+
+// objectKey key storage dari URL object, tanpa query signed URL
+func objectKey(t *testing.T, rawURL string) string {
+	t.Helper()
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parse %s: %v", rawURL, err)
+	}
+	return strings.TrimPrefix(parsed.Path, storage.LocalServePath+"/")
+}
+
+func TestUploadPublicFile(t *testing.T) {
+	s := newTestServer(t)
+	token, _ := s.register("seller@example.com")
+
+	status, body := s.upload(token, "photo.png", testutil.EncodePNG(t, 64, 32), "")
+	if status != http.StatusOK {
+		t.Fatalf("upload = %d %v", status, body)
+	}
+	if body["visibility"] != "public" {
+		t.Fatalf("visibility = %v, want public", body["visibility"])
+	}
+	if renditions := body["renditions"].([]interface{}); len(renditions) != 1 {
+		t.Fatalf("renditions = %v, want 1", renditions)
+	}
+
+	uri := body["fileUri"].(string)
+	if key := objectKey(t, uri); !strings.HasPrefix(key, storage.PublicPrefix) {
+		t.Fatalf("object key = %s, want prefix %s", key, storage.PublicPrefix)
+	}
+	rec := s.fetch(uri)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d", uri, rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != storage.ImmutableCacheControl {
+		t.Fatalf("Cache-Control = %q, want %q", got, storage.ImmutableCacheControl)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Fatalf("Content-Type = %q, want image/png", got)
+	}
+	if rec := s.fetch(body["fileThumbnailUri"].(string)); rec.Code != http.StatusOK {
+		t.Fatalf("GET thumbnail = %d", rec.Code)
+	}
+}
+
+func TestUploadRejectsInvalidFiles(t *testing.T) {
+	s := newTestServer(t)
+	token, _ := s.register("seller@example.com")
+	valid := testutil.EncodePNG(t, 8, 8)
+
+	tests := []struct {
+		name       string
+		token      string
+		filename   string
+		data       []byte
+		visibility string
+		want       int
+	}{
+		{name: "without token", filename: "photo.png", data: valid, want: http.StatusUnauthorized},
+		{name: "mismatched extension", token: token, filename: "photo.jpg", data: valid, want: http.StatusBadRequest},
+		{name: "not an image", token: token, filename: "photo.png", data: []byte("hello world"), want: http.StatusBadRequest},
+		{name: "oversized dimensions", token: token, filename: "photo.png", data: testutil.EncodePNG(t, storage.MaxImageDimension+1, 1), want: http.StatusBadRequest},
+		{name: "file too large", token: token, filename: "photo.png", data: append(append([]byte{}, valid...), make([]byte, 200*1024)...), want: http.StatusBadRequest},
+		{name: "unknown visibility", token: token, filename: "photo.png", data: valid, visibility: "secret", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := s.upload(tt.token, tt.filename, tt.data, tt.visibility); status != tt.want {
+				t.Fatalf("upload = %d %v, want %d", status, body, tt.want)
+			}
+		})
+	}
+
+	// Upload yang ditolak tidak boleh meninggalkan object di storage
+	count := 0
+	s.storage.List(context.Background(), "", func(storage.ObjectInfo) error {
+		count++
+		return nil
+	})
+	if count != 0 {
+		t.Fatalf("storage has %d objects after rejected uploads, want 0", count)
+	}
+}
+
+func TestServePrivateFileRequiresSignature(t *testing.T) {
+	s := newTestServer(t)
+	token, _ := s.register("buyer@example.com")
+
+	status, body := s.upload(token, "receipt.png", testutil.EncodePNG(t, 64, 32), "private")
+	if status != http.StatusOK {
+		t.Fatalf("upload private = %d %v", status, body)
+	}
+	signedURI := body["fileUri"].(string)
+	key := objectKey(t, signedURI)
+	if !strings.HasPrefix(key, storage.PrivatePrefix) {
+		t.Fatalf("object key = %s, want prefix %s", key, storage.PrivatePrefix)
+	}
+
+	rec := s.fetch(signedURI)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET signed url = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != storage.PrivateCacheControl {
+		t.Fatalf("Cache-Control = %q, want %q", got, storage.PrivateCacheControl)
+	}
+
+	// Signature rendition tidak berlaku untuk object asli
+	thumbnail, _ := url.Parse(body["fileThumbnailUri"].(string))
+	expired, err := s.storage.PresignURL(context.Background(), key, -time.Minute)
+	if err != nil {
+		t.Fatalf("PresignURL: %v", err)
+	}
+	forbidden := map[string]string{
+		"without signature":   s.storage.URL(key),
+		"tampered signature":  strings.Replace(signedURI, "signature=", "signature=0", 1),
+		"other key signature": s.storage.URL(key) + "?" + thumbnail.RawQuery,
+		"expired signature":   expired,
+	}
+	for name, rawURL := range forbidden {
+		if rec := s.fetch(rawURL); rec.Code != http.StatusForbidden {
+			t.Fatalf("GET %s = %d, want 403", name, rec.Code)
+		}
+	}
+}
+
+func TestServeObjectHidesIncomingAndMissingKeys(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	incoming := storage.IncomingPrefix + "1/raw.png"
+	if err := s.storage.Put(ctx, incoming, bytes.NewReader(testutil.EncodePNG(t, 8, 8)), storage.PutOptions{ContentType: "image/png"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	signed, err := s.storage.PresignURL(ctx, incoming, time.Minute)
+	if err != nil {
+		t.Fatalf("PresignURL: %v", err)
+	}
+
+	notFound := map[string]string{
+		"incoming":        s.storage.URL(incoming),
+		"signed incoming": signed,
+		"missing public":  s.storage.URL(storage.PublicPrefix + "missing.png"),
+	}
+	for name, rawURL := range notFound {
+		if rec := s.fetch(rawURL); rec.Code != http.StatusNotFound {
+			t.Fatalf("GET %s = %d, want 404", name, rec.Code)
+		}
+	}
+}
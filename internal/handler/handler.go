@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"sprint3/internal/middleware"
+	"sprint3/internal/service"
+	"sprint3/internal/storage"
+	"sprint3/pkg/config"
+)
+
+// Handler semua handler HTTP beserta dependensinya, route-nya didaftarkan di package api/v1
+type Handler struct {
+	svc     *service.Service
+	tokens  *middleware.TokenSigner
+	storage *storage.Client
+	cfg     *config.Config
+}
+
+func New(cfg *config.Config, svc *service.Service, tokens *middleware.TokenSigner, store *storage.Client) *Handler {
+	return &Handler{svc: svc, tokens: tokens, storage: store, cfg: cfg}
+}
@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	v1 "sprint3/api/v1"
+	"sprint3/internal/handler"
+	"sprint3/internal/middleware"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/service"
+	"sprint3/internal/storage"
+	"sprint3/internal/testutil"
+	"testing"
+)
+
+// testServer router /v1 dengan repository dan storage di memori, tanpa database
+type testServer struct {
+	t       *testing.T
+	router  *gin.Engine
+	storage *storage.Client
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := testutil.Config()
+	store := testutil.MemoryStorage(cfg)
+	tokens, err := middleware.NewTokenSigner(cfg)
+	if err != nil {
+		t.Fatalf("NewTokenSigner: %v", err)
+	}
+	svc := service.New(cfg, nil, repository.NewMemory(), store, notifier.LogNotifier{})
+	h := handler.New(cfg, svc, tokens, store)
+	mw := middleware.New(cfg, tokens, svc)
+
+	router := gin.New()
+	router.GET(storage.LocalServePath+"/*key", h.ServeObjectHandler)
+	group := router.Group("/v1")
+	v1.RegisterUserRouter(group, h, mw)
+	v1.RegisterAuthRoutes(group, h, mw)
+	v1.RegisterFileRoutes(group, h, mw)
+	return &testServer{t: t, router: router, storage: store}
+}
+
+// do mengirim request JSON dan mengembalikan status serta body yang sudah di-decode
+func (s *testServer) do(method, path, token string, body interface{}) (int, map[string]interface{}) {
+	s.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var decoded map[string]interface{}
+	if rec.Body.Len() > 0 {
+		json.Unmarshal(rec.Body.Bytes(), &decoded)
+	}
+	return rec.Code, decoded
+}
+
+// register membuat akun email baru dan mengembalikan access token serta refresh token-nya
+func (s *testServer) register(email string) (string, string) {
+	s.t.Helper()
+	status, body := s.do(http.MethodPost, "/v1/register/email", "", gin.H{"email": email, "password": "password123"})
+	if status != http.StatusOK {
+		s.t.Fatalf("register %s = %d %v", email, status, body)
+	}
+	return body["token"].(string), body["refreshToken"].(string)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+// HealthzHandler liveness: proses hidup dan bisa melayani request, dependensi tidak dicek
+func (h *Handler) HealthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReadyzHandler readiness: 200 kalau database dan storage bisa dipakai, 503 kalau salah satunya gagal.
+// Detail error hanya di-log supaya alamat internal dependensi tidak bocor ke publik.
+func (h *Handler) ReadyzHandler(c *gin.Context) {
+	status, code := "ok", http.StatusOK
+	checks := gin.H{}
+	for _, result := range h.svc.CheckDependencies(c.Request.Context(), h.cfg.ReadinessTimeout) {
+		check := gin.H{"status": "up", "latencyMs": result.Latency.Milliseconds()}
+		if result.Err != nil {
+			log.Printf("Readiness check %s failed: %v", result.Name, result.Err)
+			check["status"] = "down"
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+		checks[result.Name] = check
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
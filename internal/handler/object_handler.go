@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sprint3/internal/storage"
+	"strconv"
+	"strings"
+)
+
+// ServeObjectHandler melayani object dari backend local/memory yang tidak punya server sendiri.
+// Upload mentah di bawah storage.IncomingPrefix tidak dilayani, object private butuh signed URL.
+func (h *Handler) ServeObjectHandler(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if key == "" || storage.IsIncomingKey(key) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	cacheControl := storage.ImmutableCacheControl
+	if storage.IsPrivateKey(key) {
+		verifier, ok := h.storage.Storage.(storage.SignedURLVerifier)
+		if !ok || verifier.VerifySignedURL(http.MethodGet, key, c.Request.URL.Query()) != nil {
+			c.Status(http.StatusForbidden)
+			return
+		}
+		// Jangan disimpan di cache bersama, URL-nya berumur pendek
+		cacheControl = storage.PrivateCacheControl
+	}
+
+	ctx := c.Request.Context()
+	info, err := h.storage.Stat(ctx, key)
+	if err != nil {
+		objectError(c, key, err)
+		return
+	}
+	body, err := h.storage.Get(ctx, key)
+	if err != nil {
+		objectError(c, key, err)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", cacheControl)
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, nil)
+}
+
+// PutObjectHandler menerima upload langsung ke backend local/memory lewat URL hasil PresignPutURL
+func (h *Handler) PutObjectHandler(c *gin.Context) {
+	verifier, ok := h.storage.Storage.(storage.SignedURLVerifier)
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := verifier.VerifySignedURL(http.MethodPut, key, c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired upload URL"})
+		return
+	}
+
+	maxSize := h.storage.Presign.MaxUploadSize
+	if c.Request.ContentLength > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File size exceeds the upload limit of " + strconv.FormatInt(maxSize, 10) + " bytes"})
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+
+	opts := storage.PutOptions{ContentType: c.ContentType()}
+	if err := h.storage.Put(c.Request.Context(), key, body, opts); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File size exceeds the upload limit of " + strconv.FormatInt(maxSize, 10) + " bytes"})
+			return
+		}
+		log.Printf("Failed to store object %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func objectError(c *gin.Context, key string, err error) {
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	log.Printf("Failed to read object %s: %v", key, err)
+	c.Status(http.StatusInternalServerError)
+}
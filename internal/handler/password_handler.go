@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sprint3/internal/middleware"
+	"sprint3/internal/notifier"
+	"sprint3/internal/service"
+)
+
+// ForgotPasswordRequest isi salah satu dari email atau phone
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+	Phone string `json:"phone"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=32"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8,max=32"`
+}
+
+// ForgotPasswordHandler selalu membalas sukses tanpa menunggu pengiriman token,
+// supaya tidak bisa dipakai mengecek akun terdaftar
+func (h *Handler) ForgotPasswordHandler(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch {
+	case req.Email != "" && req.Phone == "":
+		h.svc.RequestPasswordReset(notifier.ChannelEmail, req.Email)
+	case req.Phone != "" && req.Email == "":
+		if !isValidPhone(req.Phone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone format. It must start with '+' followed by digits."})
+			return
+		}
+		h.svc.RequestPasswordReset(notifier.ChannelSMS, req.Phone)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either email or phone is required"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a reset token has been sent"})
+}
+
+func (h *Handler) ResetPasswordHandler(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.ResetPassword(req.Token, req.Password); err != nil {
+		log.Printf("Service error: %v", err)
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+// ChangePasswordHandler mengganti password user yang login, session lain ikut logout
+func (h *Handler) ChangePasswordHandler(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal := middleware.MustPrincipal(c)
+	err := h.svc.ChangePassword(principal.UserID, principal.SessionID, req.OldPassword, req.NewPassword)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if errors.Is(err, service.ErrInvalidPassword) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sprint3/internal/middleware"
+	"sprint3/internal/storage"
+	"time"
+)
+
+type PresignUploadRequest struct {
+	ContentType string `json:"contentType" binding:"required,oneof=image/jpeg image/png image/gif"`
+}
+
+type CompleteUploadRequest struct {
+	Key        string `json:"key" binding:"required"`
+	Visibility string `json:"visibility"`
+}
+
+// PresignUploadHandler memberikan URL PUT berumur pendek supaya client bisa upload langsung ke storage
+func (h *Handler) PresignUploadHandler(c *gin.Context) {
+	var req PresignUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	upload, err := h.storage.PresignUpload(c.Request.Context(), middleware.MustPrincipal(c).UserID, req.ContentType)
+	if err != nil {
+		log.Printf("Presign error: %v", err)
+		if errors.Is(err, storage.ErrUnsupportedImageType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Only jpeg, jpg, png (and gif if enabled) allowed."})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload URL"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"key":           upload.Key,
+		"uploadUrl":     upload.URL,
+		"method":        http.MethodPut,
+		"headers":       gin.H{"Content-Type": upload.ContentType},
+		"maxUploadSize": h.storage.Presign.MaxUploadSize,
+		"expiresAt":     upload.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// CompleteUploadHandler memproses object yang sudah diunggah lewat presigned URL:
+// validasi ukuran dan tipe, membuat rendition, lalu mencatat file ke database.
+func (h *Handler) CompleteUploadHandler(c *gin.Context) {
+	var req CompleteUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	visibility, ok := parseVisibility(c, req.Visibility)
+	if !ok {
+		return
+	}
+
+	// Key milik user lain dianggap tidak ada
+	if !storage.IsIncomingKeyOf(req.Key, middleware.MustPrincipal(c).UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	info, err := h.storage.Stat(ctx, req.Key)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+		return
+	} else if err != nil {
+		log.Printf("Failed to stat uploaded object %s: %v", req.Key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+
+	// Object mentah selalu dihapus, hasil proses disimpan dengan key baru
+	defer h.storage.DeleteObjects([]string{req.Key})
+
+	maxSize := h.storage.Presign.MaxUploadSize
+	if info.Size > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File size exceeds the upload limit"})
+		return
+	}
+
+	body, err := h.storage.Get(ctx, req.Key)
+	if err != nil {
+		log.Printf("Failed to open uploaded object %s: %v", req.Key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		log.Printf("Failed to read uploaded object %s: %v", req.Key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	if int64(len(data)) > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File size exceeds the upload limit"})
+		return
+	}
+
+	storedFile, ok := h.saveImage(c, data, path.Ext(req.Key), visibility)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, fileResponse(storedFile))
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultProductLimit = 5
+	maxProductLimit     = 100
+)
+
+type ProductRequest struct {
+	Name     string `json:"name" binding:"required,min=4,max=32"`
+	Category string `json:"category" binding:"required"`
+	Qty      int    `json:"qty" binding:"required,min=1"`
+	Price    int    `json:"price" binding:"required,min=100"`
+	Sku      string `json:"sku" binding:"required,max=32"`
+	FileId   string `json:"fileId" binding:"required,numeric"`
+}
+
+func (h *Handler) CreateProductHandler(c *gin.Context) {
+	log.Println("Handler CreateProductHandler hit")
+	product, ok := bindProductRequest(c)
+	if !ok {
+		return
+	}
+	product.UserId = middleware.MustPrincipal(c).UserID
+
+	created, err := h.svc.CreateProduct(product)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	log.Printf("Product created: ID = %d", created.ProductId)
+	c.JSON(http.StatusCreated, productResponse(created))
+}
+
+func (h *Handler) UpdateProductHandler(c *gin.Context) {
+	log.Println("Handler UpdateProductHandler hit")
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	product, ok := bindProductRequest(c)
+	if !ok {
+		return
+	}
+	product.ProductId = productID
+	product.UserId = middleware.MustPrincipal(c).UserID
+
+	updated, err := h.svc.UpdateProduct(product)
+	if err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	log.Printf("Product updated: ID = %d", updated.ProductId)
+	c.JSON(http.StatusOK, productResponse(updated))
+}
+
+func (h *Handler) DeleteProductHandler(c *gin.Context) {
+	log.Println("Handler DeleteProductHandler hit")
+	productID, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteProduct(productID, middleware.MustPrincipal(c).UserID); err != nil {
+		handleProductError(c, err)
+		return
+	}
+
+	log.Printf("Product deleted: ID = %d", productID)
+	c.JSON(http.StatusOK, gin.H{"message": "Product deleted"})
+}
+
+// GetProductsHandler endpoint publik, query param yang tidak valid diabaikan
+func (h *Handler) GetProductsHandler(c *gin.Context) {
+	filter := service.ProductFilter{
+		Limit:    defaultProductLimit,
+		Sku:      c.Query("sku"),
+		Category: c.Query("category"),
+		SortBy:   c.Query("sortBy"),
+	}
+
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= maxProductLimit {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil && offset >= 0 {
+		filter.Offset = offset
+	}
+	if id, err := strconv.ParseUint(c.Query("productId"), 10, 32); err == nil {
+		productID := uint(id)
+		filter.ProductId = &productID
+	}
+	if !model.IsValidProductCategory(filter.Category) {
+		filter.Category = ""
+	}
+
+	products, err := h.svc.GetProducts(filter)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	response := make([]gin.H, 0, len(products))
+	for i := range products {
+		response = append(response, productResponse(&products[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func bindProductRequest(c *gin.Context) (*model.Product, bool) {
+	var req ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("JSON binding failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if !model.IsValidProductCategory(req.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+		return nil, false
+	}
+
+	fileID, err := strconv.ParseUint(req.FileId, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fileId"})
+		return nil, false
+	}
+
+	return &model.Product{
+		Name:     req.Name,
+		Category: req.Category,
+		Qty:      req.Qty,
+		Price:    req.Price,
+		Sku:      req.Sku,
+		FileId:   uint(fileID),
+	}, true
+}
+
+func parseProductID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("productId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func handleProductError(c *gin.Context, err error) {
+	log.Printf("Service error: %v", err)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	} else if errors.Is(err, service.ErrNotProductOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Product belongs to another user"})
+	} else if errors.Is(err, service.ErrFileNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File not found"})
+	} else if errors.Is(err, service.ErrFileNotPublic) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product image must be a public file"})
+	} else if errors.Is(err, service.ErrSkuAlreadyExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU already exists"})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func productResponse(product *model.Product) gin.H {
+	return gin.H{
+		"productId":        strconv.FormatUint(uint64(product.ProductId), 10),
+		"name":             product.Name,
+		"category":         product.Category,
+		"qty":              product.Qty,
+		"price":            product.Price,
+		"sku":              product.Sku,
+		"fileId":           strconv.FormatUint(uint64(product.FileId), 10),
+		"fileUri":          product.FileUri,
+		"fileThumbnailUri": product.FileThumbnailUri,
+		"sold":             product.Sold,
+		"createdAt":        product.CreatedAt.Format(time.RFC3339),
+		"updatedAt":        product.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"net/mail"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
+	"strconv"
+	"time"
+)
+
+type PurchaseItemRequest struct {
+	ProductId string `json:"productId" binding:"required,numeric"`
+	Qty       int    `json:"qty" binding:"required,min=1"`
+}
+
+type PurchaseRequest struct {
+	PurchasedItems      []PurchaseItemRequest `json:"purchasedItems" binding:"required,min=1,dive"`
+	SenderName          string                `json:"senderName" binding:"required,min=4,max=55"`
+	SenderContactType   string                `json:"senderContactType" binding:"required,oneof=email phone"`
+	SenderContactDetail string                `json:"senderContactDetail" binding:"required"`
+}
+
+type PaymentProofRequest struct {
+	FileIds []string `json:"fileIds" binding:"required,min=1,dive,numeric"`
+}
+
+func (h *Handler) CreatePurchaseHandler(c *gin.Context) {
+	log.Println("Handler CreatePurchaseHandler hit")
+	var req PurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("JSON binding failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validasi sender contact sesuai tipenya
+	if req.SenderContactType == model.ContactTypePhone && !isValidPhone(req.SenderContactDetail) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone format. It must start with '+' followed by digits."})
+		return
+	}
+	if req.SenderContactType == model.ContactTypeEmail && !isValidEmail(req.SenderContactDetail) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	purchase := &model.Purchase{
+		UserId:              middleware.MustPrincipal(c).UserID,
+		SenderName:          req.SenderName,
+		SenderContactType:   req.SenderContactType,
+		SenderContactDetail: req.SenderContactDetail,
+	}
+	for _, item := range req.PurchasedItems {
+		productID, err := strconv.ParseUint(item.ProductId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid productId"})
+			return
+		}
+		purchase.Items = append(purchase.Items, model.PurchaseItem{ProductId: uint(productID), Qty: item.Qty})
+	}
+
+	created, err := h.svc.CreatePurchase(purchase)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	log.Printf("Purchase created: ID = %d, total = %d", created.PurchaseId, created.TotalPrice)
+	c.JSON(http.StatusCreated, purchaseResponse(created))
+}
+
+func (h *Handler) PayPurchaseHandler(c *gin.Context) {
+	log.Println("Handler PayPurchaseHandler hit")
+	purchaseID, err := strconv.ParseUint(c.Param("purchaseId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Purchase not found"})
+		return
+	}
+
+	var req PaymentProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("JSON binding failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileIDs := make([]uint, 0, len(req.FileIds))
+	for _, id := range req.FileIds {
+		fileID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fileId"})
+			return
+		}
+		fileIDs = append(fileIDs, uint(fileID))
+	}
+
+	purchase, err := h.svc.PayPurchase(uint(purchaseID), middleware.MustPrincipal(c).UserID, fileIDs)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	log.Printf("Purchase paid: ID = %d", purchase.PurchaseId)
+	c.JSON(http.StatusOK, purchaseResponse(purchase))
+}
+
+func (h *Handler) CancelPurchaseHandler(c *gin.Context) {
+	log.Println("Handler CancelPurchaseHandler hit")
+	purchaseID, err := strconv.ParseUint(c.Param("purchaseId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Purchase not found"})
+		return
+	}
+
+	purchase, err := h.svc.CancelPurchase(uint(purchaseID), middleware.MustPrincipal(c).UserID)
+	if err != nil {
+		handlePurchaseError(c, err)
+		return
+	}
+
+	log.Printf("Purchase cancelled: ID = %d, status = %s", purchase.PurchaseId, purchase.Status)
+	c.JSON(http.StatusOK, purchaseResponse(purchase))
+}
+
+func handlePurchaseError(c *gin.Context, err error) {
+	log.Printf("Service error: %v", err)
+	if errors.Is(err, service.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	} else if errors.Is(err, service.ErrInsufficientStock) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	} else if errors.Is(err, service.ErrSellerBankMissing) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Seller has not set up a bank account yet"})
+	} else if errors.Is(err, service.ErrPurchaseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Purchase not found"})
+	} else if errors.Is(err, service.ErrPurchaseAlreadyPaid) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Purchase already paid"})
+	} else if errors.Is(err, service.ErrPurchaseClosed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Purchase has been cancelled or expired"})
+	} else if errors.Is(err, service.ErrPaymentProofMismatch) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Number of fileIds must match number of paymentDetails"})
+	} else if errors.Is(err, service.ErrFileNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File not found"})
+	} else if errors.Is(err, service.ErrPaymentProofPublic) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment proof must be uploaded with visibility private"})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+func purchaseResponse(purchase *model.Purchase) gin.H {
+	items := make([]gin.H, 0, len(purchase.Items))
+	for _, item := range purchase.Items {
+		items = append(items, gin.H{
+			"productId":        strconv.FormatUint(uint64(item.ProductId), 10),
+			"name":             item.Name,
+			"category":         item.Category,
+			"qty":              item.Qty,
+			"price":            item.Price,
+			"sku":              item.Sku,
+			"fileId":           strconv.FormatUint(uint64(item.FileId), 10),
+			"fileUri":          item.FileUri,
+			"fileThumbnailUri": item.FileThumbnailUri,
+		})
+	}
+
+	payments := make([]gin.H, 0, len(purchase.Payments))
+	for _, payment := range purchase.Payments {
+		detail := gin.H{
+			"bankAccountName":   payment.BankAccountName,
+			"bankAccountHolder": payment.BankAccountHolder,
+			"bankAccountNumber": payment.BankAccountNumber,
+			"totalPrice":        payment.TotalPrice,
+			"fileId":            "",
+		}
+		if payment.FileId != nil {
+			detail["fileId"] = strconv.FormatUint(uint64(*payment.FileId), 10)
+		}
+		payments = append(payments, detail)
+	}
+
+	response := gin.H{
+		"purchaseId":     strconv.FormatUint(uint64(purchase.PurchaseId), 10),
+		"purchasedItems": items,
+		"totalPrice":     purchase.TotalPrice,
+		"paymentDetails": payments,
+		"status":         purchase.Status,
+		"createdAt":      purchase.CreatedAt.Format(time.RFC3339),
+		"expiresAt":      purchase.ExpiresAt.Format(time.RFC3339),
+		"paidAt":         "",
+		"cancelledAt":    "",
+	}
+	if purchase.PaidAt != nil {
+		response["paidAt"] = purchase.PaidAt.Format(time.RFC3339)
+	}
+	if purchase.CancelledAt != nil {
+		response["cancelledAt"] = purchase.CancelledAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func isValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
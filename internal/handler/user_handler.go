@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"log"
+	"math"
 	"net/http"
 	"regexp"
 	"sprint3/internal/middleware"
+	"sprint3/internal/model"
 	"sprint3/internal/service"
+	"strconv"
 )
 
 type AuthRequestEmail struct {
@@ -20,7 +25,24 @@ type AuthRequestPhone struct {
 	Password string `json:"password" binding:"required,min=8,max=32"`
 }
 
-func RegisterUserEmail(c *gin.Context) {
+type LinkPhoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+type LinkEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// UpdateUserProfileRequest semua field opsional, field yang tidak dikirim tidak diubah dan field
+// yang dikirim null dikosongkan
+type UpdateUserProfileRequest struct {
+	FileId            *string `json:"fileId" binding:"omitempty,numeric"`
+	BankAccountName   *string `json:"bankAccountName" binding:"omitempty,min=4,max=32"`
+	BankAccountHolder *string `json:"bankAccountHolder" binding:"omitempty,min=4,max=32"`
+	BankAccountNumber *string `json:"bankAccountNumber" binding:"omitempty,min=4,max=32,numeric"`
+}
+
+func (h *Handler) RegisterUserEmail(c *gin.Context) {
 	log.Println("Handler RegisterUserEmail hit")
 	var req AuthRequestEmail
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -29,8 +51,7 @@ func RegisterUserEmail(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Input validated: %+v", req)
-	user, err := service.RegisterUserEmail(req.Email, req.Password)
+	user, err := h.svc.RegisterUserEmail(req.Email, req.Password)
 	if err != nil {
 		log.Printf("Service error: %v", err)
 		if errors.Is(err, service.ErrEmailAlreadyExists) {
@@ -41,7 +62,7 @@ func RegisterUserEmail(c *gin.Context) {
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.Email, user.Phone, user.Id)
+	token, refreshToken, err := h.issueTokens(user)
 	if err != nil {
 		log.Printf("Token generation failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -51,8 +72,10 @@ func RegisterUserEmail(c *gin.Context) {
 	log.Println("User registered successfully")
 	// Prepare response
 	response := gin.H{
-		"email": user.Email,
-		"token": token,
+		"email":        user.Email,
+		"token":        token,
+		"refreshToken": refreshToken,
+		"expiresIn":    int(h.tokens.AccessTokenTTL().Seconds()),
 	}
 
 	// If phone is nil, set it to an empty string
@@ -65,7 +88,7 @@ func RegisterUserEmail(c *gin.Context) {
 	// Return response
 	c.JSON(http.StatusOK, response)
 }
-func LoginUserEmail(c *gin.Context) {
+func (h *Handler) LoginUserEmail(c *gin.Context) {
 	log.Println("Handler LoginUserEmail hit")
 	var req AuthRequestEmail
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -74,21 +97,13 @@ func LoginUserEmail(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Input validated: %+v", req)
-	user, err := service.AuthenticateEmail(req.Email, req.Password)
+	user, err := h.svc.AuthenticateEmail(req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		log.Printf("Authentication error: %v", err)
-		if errors.Is(err, service.ErrEmailNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Email not found"})
-		} else if errors.Is(err, service.ErrInvalidPassword) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		h.handleLoginError(c, err, service.ErrEmailNotFound, "Email not found")
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.Email, user.Phone, user.Id)
+	token, refreshToken, err := h.issueTokens(user)
 	if err != nil {
 		log.Printf("Token generation failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -98,8 +113,10 @@ func LoginUserEmail(c *gin.Context) {
 	log.Println("User logged in successfully")
 	// Prepare response
 	response := gin.H{
-		"email": user.Email,
-		"token": token,
+		"email":        user.Email,
+		"token":        token,
+		"refreshToken": refreshToken,
+		"expiresIn":    int(h.tokens.AccessTokenTTL().Seconds()),
 	}
 
 	// If phone is nil, set it to an empty string
@@ -113,7 +130,7 @@ func LoginUserEmail(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func RegisterUserPhone(c *gin.Context) {
+func (h *Handler) RegisterUserPhone(c *gin.Context) {
 	log.Println("Handler RegisterUserPhone hit")
 	var req AuthRequestPhone
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -129,8 +146,7 @@ func RegisterUserPhone(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Input validated: %+v", req)
-	user, err := service.RegisterUserPhone(req.Phone, req.Password)
+	user, err := h.svc.RegisterUserPhone(req.Phone, req.Password)
 	if err != nil {
 		log.Printf("Service error: %v", err)
 		if errors.Is(err, service.ErrPhoneAlreadyExists) {
@@ -141,7 +157,7 @@ func RegisterUserPhone(c *gin.Context) {
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.Email, user.Phone, user.Id)
+	token, refreshToken, err := h.issueTokens(user)
 	if err != nil {
 		log.Printf("Token generation failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -151,8 +167,10 @@ func RegisterUserPhone(c *gin.Context) {
 	log.Println("User registered successfully")
 	// Prepare response
 	response := gin.H{
-		"phone": user.Phone,
-		"token": token,
+		"phone":        user.Phone,
+		"token":        token,
+		"refreshToken": refreshToken,
+		"expiresIn":    int(h.tokens.AccessTokenTTL().Seconds()),
 	}
 
 	// If phone is nil, set it to an empty string
@@ -165,7 +183,7 @@ func RegisterUserPhone(c *gin.Context) {
 	// Return response
 	c.JSON(http.StatusOK, response)
 }
-func LoginUserPhone(c *gin.Context) {
+func (h *Handler) LoginUserPhone(c *gin.Context) {
 	log.Println("Handler LoginUserPhone hit")
 	var req AuthRequestPhone
 
@@ -177,17 +195,9 @@ func LoginUserPhone(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Input validated: %+v", req)
-	user, err := service.AuthenticatePhone(req.Phone, req.Password)
+	user, err := h.svc.AuthenticatePhone(req.Phone, req.Password, c.ClientIP())
 	if err != nil {
-		log.Printf("Authentication error: %v", err)
-		if errors.Is(err, service.ErrPhoneNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Phone not found"})
-		} else if errors.Is(err, service.ErrInvalidPassword) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		}
+		h.handleLoginError(c, err, service.ErrPhoneNotFound, "Phone not found")
 		return
 	}
 	if !isValidPhone(req.Phone) {
@@ -195,7 +205,7 @@ func LoginUserPhone(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone format. It must start with '+' followed by digits."})
 		return
 	}
-	token, err := middleware.GenerateToken(user.Email, user.Phone, user.Id)
+	token, refreshToken, err := h.issueTokens(user)
 	if err != nil {
 		log.Printf("Token generation failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -205,8 +215,10 @@ func LoginUserPhone(c *gin.Context) {
 	log.Println("User logged in successfully")
 	// Prepare response
 	response := gin.H{
-		"phone": user.Phone,
-		"token": token,
+		"phone":        user.Phone,
+		"token":        token,
+		"refreshToken": refreshToken,
+		"expiresIn":    int(h.tokens.AccessTokenTTL().Seconds()),
 	}
 
 	// If phone is nil, set it to an empty string
@@ -219,6 +231,200 @@ func LoginUserPhone(c *gin.Context) {
 	// Return response
 	c.JSON(http.StatusOK, response)
 }
+func (h *Handler) GetUserProfileHandler(c *gin.Context) {
+	userID := middleware.MustPrincipal(c).UserID
+
+	profile, err := h.svc.GetUserProfile(userID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, userProfileResponse(profile))
+}
+
+func (h *Handler) UpdateUserProfileHandler(c *gin.Context) {
+	log.Println("Handler UpdateUserProfileHandler hit")
+	userID := middleware.MustPrincipal(c).UserID
+
+	// Body dibaca sekali supaya bisa dibind ke request sekaligus dicek field mana yang dikirim null
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	var req UpdateUserProfileRequest
+	if err := binding.JSON.BindBody(body, &req); err != nil {
+		log.Printf("JSON binding failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	isNull := func(field string) bool {
+		value, ok := fields[field]
+		return ok && string(value) == "null"
+	}
+
+	update := service.UserProfileUpdate{
+		BankAccountName:        req.BankAccountName,
+		BankAccountHolder:      req.BankAccountHolder,
+		BankAccountNumber:      req.BankAccountNumber,
+		ClearFileId:            isNull("fileId"),
+		ClearBankAccountName:   isNull("bankAccountName"),
+		ClearBankAccountHolder: isNull("bankAccountHolder"),
+		ClearBankAccountNumber: isNull("bankAccountNumber"),
+	}
+	if req.FileId != nil {
+		fileID, err := strconv.ParseUint(*req.FileId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fileId"})
+			return
+		}
+		id := uint(fileID)
+		update.FileId = &id
+	}
+
+	profile, err := h.svc.UpdateUserProfile(userID, update)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if errors.Is(err, service.ErrFileNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File not found"})
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	log.Println("User profile updated successfully")
+	c.JSON(http.StatusOK, userProfileResponse(profile))
+}
+
+func (h *Handler) LinkPhoneHandler(c *gin.Context) {
+	log.Println("Handler LinkPhoneHandler hit")
+	userID := middleware.MustPrincipal(c).UserID
+
+	var req LinkPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("JSON binding failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !isValidPhone(req.Phone) {
+		log.Println("Phone validation failed")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone format. It must start with '+' followed by digits."})
+		return
+	}
+
+	profile, err := h.svc.LinkPhone(userID, req.Phone)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if errors.Is(err, service.ErrPhoneAlreadyExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Phone already exists"})
+		} else if errors.Is(err, service.ErrContactAlreadySet) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Phone is already set for this account"})
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	log.Println("Phone linked successfully")
+	c.JSON(http.StatusOK, userProfileResponse(profile))
+}
+
+func (h *Handler) LinkEmailHandler(c *gin.Context) {
+	log.Println("Handler LinkEmailHandler hit")
+	userID := middleware.MustPrincipal(c).UserID
+
+	var req LinkEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("JSON binding failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.svc.LinkEmail(userID, req.Email)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if errors.Is(err, service.ErrEmailAlreadyExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+		} else if errors.Is(err, service.ErrContactAlreadySet) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already set for this account"})
+		} else if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	log.Println("Email linked successfully")
+	c.JSON(http.StatusOK, userProfileResponse(profile))
+}
+
+// handleLoginError dengan LOGIN_UNIFORM_ERRORS akun tidak ditemukan dan password salah
+// dibalas sama supaya tidak bisa dipakai mengecek akun terdaftar
+func (h *Handler) handleLoginError(c *gin.Context, err, errNotFound error, notFoundMessage string) {
+	log.Printf("Authentication error: %v", err)
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+	} else if h.cfg.LoginUniformErrors && (errors.Is(err, errNotFound) || errors.Is(err, service.ErrInvalidPassword)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	} else if errors.Is(err, service.ErrUserSuspended) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+	} else if errors.Is(err, errNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFoundMessage})
+	} else if errors.Is(err, service.ErrInvalidPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// userProfileResponse menyusun response profil, field yang NULL dikirim sebagai string kosong
+func userProfileResponse(profile *model.UserProfile) gin.H {
+	response := gin.H{
+		"email":            stringOrEmpty(profile.Email),
+		"phone":            stringOrEmpty(profile.Phone),
+		"fileId":           "",
+		"fileUri":          stringOrEmpty(profile.FileUri),
+		"fileThumbnailUri": stringOrEmpty(profile.FileThumbnailUri),
+		"emailVerified":    profile.EmailVerified,
+		"phoneVerified":    profile.PhoneVerified,
+
+		"bankAccountName":   stringOrEmpty(profile.BankAccountName),
+		"bankAccountHolder": stringOrEmpty(profile.BankAccountHolder),
+		"bankAccountNumber": stringOrEmpty(profile.BankAccountNumber),
+	}
+	if profile.FileId != nil {
+		response["fileId"] = strconv.FormatUint(uint64(*profile.FileId), 10)
+	}
+	return response
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func isValidPhone(phone string) bool {
 	// Validasi nomor telepon: harus dimulai dengan "+" diikuti angka
 	match, _ := regexp.MatchString(`^\+\d+$`, phone)
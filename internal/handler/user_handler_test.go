@@ -0,0 +1,135 @@
+package handler_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sprint3/internal/testutil"
+	"testing"
+)
+
+func TestRegisterLoginAndProfile(t *testing.T) {
+	s := newTestServer(t)
+	s.register("buyer@example.com")
+
+	status, body := s.do(http.MethodPost, "/v1/register/email", "", gin.H{"email": "buyer@example.com", "password": "password123"})
+	if status != http.StatusConflict {
+		t.Fatalf("duplicate register = %d %v, want 409", status, body)
+	}
+
+	status, body = s.do(http.MethodPost, "/v1/login/email", "", gin.H{"email": "buyer@example.com", "password": "wrongpassword"})
+	if status != http.StatusUnauthorized {
+		t.Fatalf("login with wrong password = %d %v, want 401", status, body)
+	}
+
+	status, body = s.do(http.MethodPost, "/v1/login/email", "", gin.H{"email": "buyer@example.com", "password": "password123"})
+	if status != http.StatusOK {
+		t.Fatalf("login = %d %v", status, body)
+	}
+	token := body["token"].(string)
+
+	status, body = s.do(http.MethodGet, "/v1/user", token, nil)
+	if status != http.StatusOK || body["email"] != "buyer@example.com" {
+		t.Fatalf("profile = %d %v, want buyer@example.com", status, body)
+	}
+
+	status, _ = s.do(http.MethodGet, "/v1/user", "", nil)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("profile without token = %d, want 401", status)
+	}
+}
+
+func TestLinkPhoneConflict(t *testing.T) {
+	s := newTestServer(t)
+	first, _ := s.register("first@example.com")
+	second, _ := s.register("second@example.com")
+
+	status, body := s.do(http.MethodPost, "/v1/user/link/phone", first, gin.H{"phone": "+628111"})
+	if status != http.StatusOK || body["phone"] != "+628111" {
+		t.Fatalf("link phone = %d %v", status, body)
+	}
+
+	status, body = s.do(http.MethodPost, "/v1/user/link/phone", second, gin.H{"phone": "+628111"})
+	if status != http.StatusConflict {
+		t.Fatalf("link taken phone = %d %v, want 409", status, body)
+	}
+
+	status, body = s.do(http.MethodPost, "/v1/user/link/phone", first, gin.H{"phone": "+628222"})
+	if status != http.StatusConflict {
+		t.Fatalf("replace linked phone = %d %v, want 409", status, body)
+	}
+	status, body = s.do(http.MethodPost, "/v1/user/link/email", first, gin.H{"email": "other@example.com"})
+	if status != http.StatusConflict {
+		t.Fatalf("replace registered email = %d %v, want 409", status, body)
+	}
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	s := newTestServer(t)
+	token, refreshToken := s.register("buyer@example.com")
+
+	status, body := s.do(http.MethodPost, "/v1/auth/refresh", "", gin.H{"refreshToken": refreshToken})
+	if status != http.StatusOK {
+		t.Fatalf("refresh = %d %v", status, body)
+	}
+	status, _ = s.do(http.MethodPost, "/v1/auth/refresh", "", gin.H{"refreshToken": refreshToken})
+	if status != http.StatusUnauthorized {
+		t.Fatalf("refresh with used token = %d, want 401", status)
+	}
+
+	// Reuse di atas mencabut session pertama, jadi token-nya ikut ditolak
+	status, _ = s.do(http.MethodGet, "/v1/user", token, nil)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("profile after refresh token reuse = %d, want 401", status)
+	}
+
+	token, _ = s.register("other@example.com")
+	if status, body := s.do(http.MethodPost, "/v1/logout", token, nil); status != http.StatusOK {
+		t.Fatalf("logout = %d %v", status, body)
+	}
+	status, _ = s.do(http.MethodGet, "/v1/user", token, nil)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("profile after logout = %d, want 401", status)
+	}
+}
+
+func TestUpdateProfileClearsNullFields(t *testing.T) {
+	s := newTestServer(t)
+	token, _ := s.register("seller@example.com")
+	status, body := s.upload(token, "photo.png", testutil.EncodePNG(t, 16, 16), "")
+	if status != http.StatusOK {
+		t.Fatalf("upload = %d %v", status, body)
+	}
+	fileID := body["fileId"].(string)
+
+	status, body = s.do(http.MethodPatch, "/v1/user", token, gin.H{
+		"fileId":            fileID,
+		"bankAccountName":   "BCA Syariah",
+		"bankAccountHolder": "Seller",
+		"bankAccountNumber": "1234567890",
+	})
+	if status != http.StatusOK || body["fileId"] != fileID || body["fileUri"] == "" || body["bankAccountName"] != "BCA Syariah" {
+		t.Fatalf("set profile = %d %v", status, body)
+	}
+
+	// Field yang tidak dikirim tetap, field yang dikirim null dikosongkan
+	status, body = s.do(http.MethodPatch, "/v1/user", token, gin.H{"fileId": nil, "bankAccountNumber": nil})
+	if status != http.StatusOK {
+		t.Fatalf("clear profile = %d %v", status, body)
+	}
+	if body["fileId"] != "" || body["fileUri"] != "" || body["bankAccountNumber"] != "" {
+		t.Fatalf("cleared fields = %v, want fileId, fileUri and bankAccountNumber empty", body)
+	}
+	if body["bankAccountName"] != "BCA Syariah" || body["bankAccountHolder"] != "Seller" {
+		t.Fatalf("untouched fields = %v, want bank name and holder kept", body)
+	}
+
+	status, body = s.do(http.MethodGet, "/v1/user", token, nil)
+	if status != http.StatusOK || body["fileId"] != "" || body["bankAccountNumber"] != "" || body["bankAccountHolder"] != "Seller" {
+		t.Fatalf("profile after clear = %d %v", status, body)
+	}
+
+	status, body = s.do(http.MethodPatch, "/v1/user", token, gin.H{"bankAccountName": "abc"})
+	if status != http.StatusBadRequest {
+		t.Fatalf("invalid bank name = %d %v, want 400", status, body)
+	}
+}
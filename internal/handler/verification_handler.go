@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sprint3/internal/middleware"
+	"sprint3/internal/notifier"
+	"sprint3/internal/service"
+)
+
+type VerifyCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+func (h *Handler) SendEmailVerificationHandler(c *gin.Context) {
+	h.sendVerificationCode(c, notifier.ChannelEmail)
+}
+
+func (h *Handler) SendPhoneVerificationHandler(c *gin.Context) {
+	h.sendVerificationCode(c, notifier.ChannelSMS)
+}
+
+func (h *Handler) VerifyEmailHandler(c *gin.Context) {
+	h.verifyCode(c, notifier.ChannelEmail)
+}
+
+func (h *Handler) VerifyPhoneHandler(c *gin.Context) {
+	h.verifyCode(c, notifier.ChannelSMS)
+}
+
+func (h *Handler) sendVerificationCode(c *gin.Context, channel string) {
+	if err := h.svc.SendVerificationCode(middleware.MustPrincipal(c).UserID, channel); err != nil {
+		handleVerificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent"})
+}
+
+func (h *Handler) verifyCode(c *gin.Context, channel string) {
+	var req VerifyCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middleware.MustPrincipal(c).UserID
+	if err := h.svc.VerifyCode(userID, channel, req.Code); err != nil {
+		handleVerificationError(c, err)
+		return
+	}
+
+	profile, err := h.svc.GetUserProfile(userID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, userProfileResponse(profile))
+}
+
+func handleVerificationError(c *gin.Context, err error) {
+	log.Printf("Service error: %v", err)
+	switch {
+	case errors.Is(err, service.ErrContactNotSet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No email or phone to verify, link one first"})
+	case errors.Is(err, service.ErrContactAlreadyVerified):
+		c.JSON(http.StatusConflict, gin.H{"error": "Already verified"})
+	case errors.Is(err, service.ErrOTPTooSoon):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Verification code was sent recently, please wait"})
+	case errors.Is(err, service.ErrInvalidOTP):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verification code"})
+	case errors.Is(err, service.ErrOTPExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification code expired, request a new one"})
+	case errors.Is(err, service.ErrOTPAttemptsExceeded):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, request a new verification code"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -1,69 +1,147 @@
 package middleware
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"log"
 	"net/http"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
 	"sprint3/pkg/config"
-
+	"strconv"
 	"strings"
 	"time"
 )
 
-var jwtSecret = []byte(getJWTSecret())
+// TokenSigner menandatangani dan memvalidasi access token dengan key set dan iss/aud dari config
+type TokenSigner struct {
+	keys     *keySet
+	parser   *jwt.Parser
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+// NewTokenSigner memuat key JWT dari cfg, lihat loadKeys
+func NewTokenSigner(cfg *config.Config) (*TokenSigner, error) {
+	keys, err := loadKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenSigner{
+		keys: keys,
+		// Parser hanya menerima algoritma key yang terdaftar, iss/aud yang sesuai, serta exp dan iat yang valid
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(keys.methods()),
+			jwt.WithIssuer(cfg.JWTIssuer),
+			jwt.WithAudience(cfg.JWTAudience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+		ttl:      cfg.AccessTokenTTL,
+	}, nil
+}
 
-func getJWTSecret() string {
-	secret := config.LoadEnv().JWTSecret
-	if secret == "" {
-		fmt.Println("⚠️  WARNING: JWT_SECRET tidak terbaca, gunakan default untuk debugging!")
-		secret = "default-secret-key"
+// GenerateToken membuat access token berumur pendek untuk session sessionID.
+// Setiap token punya jti unik supaya bisa dicabut satu per satu saat logout.
+func (t *TokenSigner) GenerateToken(user *model.User, sessionID string) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:    user.Id,
+		Roles:     user.Roles,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   strconv.FormatUint(uint64(user.Id), 10),
+			Audience:  jwt.ClaimStrings{t.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
 	}
-	return secret
+	if user.Email != nil {
+		claims.Email = *user.Email
+	}
+	if user.Phone != nil {
+		claims.Phone = *user.Phone
+	}
+
+	// Token selalu ditandatangani key aktif, kid dipakai verifier untuk memilih public key
+	key := t.keys.active
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.signer)
+}
+
+// AccessTokenTTL umur access token, dikirim ke client sebagai expiresIn
+func (t *TokenSigner) AccessTokenTTL() time.Duration {
+	return t.ttl
 }
 
-func GenerateToken(email, phone *string, userId uint) (string, error) {
-	claims := jwt.MapClaims{
-		"email":  email,
-		"phone":  phone,
-		"userID": userId,
-		"exp":    time.Now().Add(time.Hour * 24).Unix(), // Token berlaku 1 hari
+// ParseToken memvalidasi signature dan claim access token
+func (t *TokenSigner) ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if _, err := t.parser.ParseWithClaims(tokenString, claims, t.keys.verificationKey); err != nil {
+		return nil, err
 	}
+	return claims, nil
+}
+
+// Middleware middleware yang butuh dependensi aplikasi, dibuat sekali per App
+type Middleware struct {
+	tokens *TokenSigner
+	svc    *service.Service
+	cfg    *config.Config
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+func New(cfg *config.Config, tokens *TokenSigner, svc *service.Service) *Middleware {
+	return &Middleware{tokens: tokens, svc: svc, cfg: cfg}
 }
 
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware memvalidasi access token lalu menyimpan Principal di context request, lihat MustPrincipal
+func (m *Middleware) JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
+		if !ok || tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims := jwt.MapClaims{}
-
-		token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := m.tokens.ParseToken(tokenString)
+		if err != nil {
+			log.Printf("Rejected access token: %v", err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Ambil userID dari klaim
-		if userID, ok := claims["userID"].(float64); ok {
-			c.Set("userID", uint(userID))
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token payload"})
+		// Token dari session yang sudah logout atau jti yang sudah dicabut ditolak
+		active, err := m.svc.IsTokenActive(claims.SessionID, claims.ID)
+		if err != nil {
+			log.Printf("Failed to check token revocation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			c.Abort()
 			return
 		}
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		principal := &Principal{
+			UserID:    claims.UserID,
+			SessionID: claims.SessionID,
+			TokenID:   claims.ID,
+			ExpiresAt: claims.ExpiresAt.Time,
+			Roles:     claims.Roles,
+		}
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
 
 		c.Next()
 	}
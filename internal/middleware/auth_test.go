@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"sprint3/internal/middleware"
+	"sprint3/internal/model"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/service"
+	"sprint3/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestJWTAuthMiddlewareWithPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := testutil.Config()
+	svc := service.New(cfg, nil, repository.NewMemory(), testutil.MemoryStorage(cfg), notifier.LogNotifier{})
+	tokens, err := middleware.NewTokenSigner(cfg)
+	if err != nil {
+		t.Fatalf("NewTokenSigner: %v", err)
+	}
+	mw := middleware.New(cfg, tokens, svc)
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router := gin.New()
+	router.GET("/product", mw.JWTAuthMiddleware(), middleware.RequirePermission(model.PermissionProductWrite), ok)
+	router.GET("/admin", mw.JWTAuthMiddleware(), middleware.RequirePermission(model.PermissionUserManage), ok)
+
+	user, err := svc.RegisterUserEmail("seller@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	session, err := svc.CreateSession(user.Id)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	sellerToken, err := tokens.GenerateToken(user, session.ID)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	admin := *user
+	admin.Roles = []string{model.RoleAdmin}
+	adminToken, err := tokens.GenerateToken(&admin, session.ID)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{name: "no token", path: "/product", want: http.StatusUnauthorized},
+		{name: "malformed token", path: "/product", token: "not-a-jwt", want: http.StatusUnauthorized},
+		{name: "seller writes product", path: "/product", token: sellerToken, want: http.StatusOK},
+		{name: "seller manages users", path: "/admin", token: sellerToken, want: http.StatusForbidden},
+		{name: "admin manages users", path: "/admin", token: adminToken, want: http.StatusOK},
+		{name: "admin writes product", path: "/product", token: adminToken, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := get(tt.path, tt.token); got != tt.want {
+				t.Fatalf("GET %s = %d, want %d", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	// Token dari session yang sudah dicabut ditolak walaupun role-nya cukup
+	if err := svc.RevokeSession(user.Id, session.ID, "", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if got := get("/admin", adminToken); got != http.StatusUnauthorized {
+		t.Fatalf("GET /admin with revoked session = %d, want 401", got)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"strconv"
+)
+
+var errMissingClaims = errors.New("token is missing required claims")
+
+// Claims isi access token. sub selalu sama dengan userID, jti dipakai untuk mencabut token satu per satu.
+type Claims struct {
+	UserID    uint     `json:"userID"`
+	Email     string   `json:"email,omitempty"`
+	Phone     string   `json:"phone,omitempty"`
+	Roles     []string `json:"roles"`
+	SessionID string   `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Validate dipanggil parser setelah exp, iat, iss dan aud dicek, token tanpa klaim wajib ditolak
+func (c *Claims) Validate() error {
+	if c.UserID == 0 || c.SessionID == "" || c.ID == "" || c.IssuedAt == nil {
+		return errMissingClaims
+	}
+	if c.Subject != strconv.FormatUint(uint64(c.UserID), 10) {
+		return errors.New("token subject does not match userID")
+	}
+	return nil
+}
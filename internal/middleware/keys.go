@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sprint3/pkg/config"
+	"strings"
+)
+
+// signingKey satu key JWT. Key selain yang aktif hanya dipakai untuk verifikasi,
+// jadi token lama tetap berlaku sampai kedaluwarsa setelah key dirotasi.
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	signer crypto.Signer
+}
+
+type keySet struct {
+	active *signingKey
+	byKid  map[string]*signingKey
+}
+
+// loadKeys membaca semua key dari JWTKeyDir. Di production aplikasi wajib gagal start
+// kalau tidak ada key, di luar production dibuat key Ed25519 sementara.
+func loadKeys(cfg *config.Config) (*keySet, error) {
+	keys, err := loadKeySet(cfg.JWTKeyDir, cfg.JWTActiveKeyID)
+	if err == nil {
+		log.Printf("✅ Loaded %d JWT signing key(s), active kid %s", len(keys.byKid), keys.active.kid)
+		return keys, nil
+	}
+	if cfg.IsProduction() {
+		return nil, fmt.Errorf("failed to load JWT signing keys: %v", err)
+	}
+
+	log.Printf("⚠️  WARNING: %v, using an ephemeral Ed25519 key (tokens will not survive a restart)", err)
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate JWT signing key: %v", err)
+	}
+	key := &signingKey{kid: "ephemeral", method: jwt.SigningMethodEdDSA, signer: private}
+	return &keySet{active: key, byKid: map[string]*signingKey{key.kid: key}}, nil
+}
+
+// loadKeySet membaca setiap file <kid>.pem di dir sebagai private key RSA atau Ed25519
+func loadKeySet(dir, activeKid string) (*keySet, error) {
+	if dir == "" {
+		return nil, errors.New("JWT_KEY_DIR is not set")
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.pem keys found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	keys := &keySet{byKid: map[string]*signingKey{}}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := loadSigningKey(path, kid)
+		if err != nil {
+			return nil, err
+		}
+		keys.byKid[kid] = key
+	}
+
+	// Tanpa JWT_ACTIVE_KID dipakai key dengan nama file terakhir, misal 2024-06.pem setelah 2024-01.pem
+	if activeKid == "" {
+		activeKid = strings.TrimSuffix(filepath.Base(paths[len(paths)-1]), ".pem")
+	}
+	keys.active = keys.byKid[activeKid]
+	if keys.active == nil {
+		return nil, fmt.Errorf("active JWT key %q not found in %s", activeKid, dir)
+	}
+	return keys, nil
+}
+
+func loadSigningKey(path, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("%s: RSA key must be at least 2048 bits", path)
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, signer: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, signer: key}, nil
+	default:
+		return nil, fmt.Errorf("%s: only RSA and Ed25519 keys are supported", path)
+	}
+}
+
+// verificationKey dipakai jwt.Parse untuk memilih public key berdasarkan header kid
+func (k *keySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.byKid[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	// Algoritma harus sesuai key, supaya header alg tidak bisa dipalsukan
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for kid %q", token.Method.Alg(), kid)
+	}
+	return key.signer.Public(), nil
+}
+
+// methods algoritma yang dipakai key di set ini, token dengan algoritma lain langsung ditolak parser
+func (k *keySet) methods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range k.byKid {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS public key semua key dalam format JSON Web Key Set (RFC 7517)
+func (t *TokenSigner) JWKS() map[string]interface{} {
+	kids := make([]string, 0, len(t.keys.byKid))
+	for kid := range t.keys.byKid {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]map[string]string, 0, len(kids))
+	for _, kid := range kids {
+		key := t.keys.byKid[kid]
+		jwk := map[string]string{"kid": kid, "use": "sig", "alg": key.method.Alg()}
+		switch public := key.signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(public)
+		}
+		keys = append(keys, jwk)
+	}
+	return map[string]interface{}{"keys": keys}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"sprint3/internal/model"
+	"time"
+)
+
+// Principal user yang sudah diautentikasi JWTAuthMiddleware untuk satu request
+type Principal struct {
+	UserID    uint
+	SessionID string
+	// TokenID jti access token, ExpiresAt dipakai saat token dicabut
+	TokenID   string
+	ExpiresAt time.Time
+	Roles     []string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasPermission(permission string) bool {
+	return model.HasPermission(p.Roles, permission)
+}
+
+type principalKey struct{}
+
+// WithPrincipal menyimpan principal di context, dibawa sampai ke service lewat c.Request.Context()
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// CurrentPrincipal principal request, false kalau route tidak melewati JWTAuthMiddleware
+func CurrentPrincipal(c *gin.Context) (*Principal, bool) {
+	return PrincipalFromContext(c.Request.Context())
+}
+
+// MustPrincipal hanya untuk handler di belakang JWTAuthMiddleware, panic kalau route salah dikonfigurasi
+func MustPrincipal(c *gin.Context) *Principal {
+	principal, ok := CurrentPrincipal(c)
+	if !ok {
+		panic("middleware: no principal in request context, route is missing JWTAuthMiddleware")
+	}
+	return principal
+}
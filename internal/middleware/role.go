@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequireRole hanya meneruskan request kalau user punya salah satu role. Dipasang setelah JWTAuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := MustPrincipal(c)
+		for _, role := range roles {
+			if principal.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+		c.Abort()
+	}
+}
+
+// RequirePermission hanya meneruskan request kalau salah satu role user punya permission, lihat model.RolePermissions
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !MustPrincipal(c).HasPermission(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permission"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
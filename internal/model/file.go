@@ -1,7 +1,27 @@
 package model
 
+import "time"
+
+const (
+	FileVisibilityPublic  = "public"
+	FileVisibilityPrivate = "private"
+)
+
 type File struct {
-	ID           int    `json:"id"`
-	URI          string `json:"uri"`
-	ThumbnailURI string `json:"thumbnailUri"`
+	ID           int             `json:"id"`
+	UserId       uint            `json:"-"`
+	URI          string          `json:"uri"`
+	ThumbnailURI string          `json:"thumbnailUri"`
+	Renditions   []FileRendition `json:"renditions"`
+	// Visibility public (URL permanen) atau private (signed URL yang dibuat saat dibaca)
+	Visibility string `json:"visibility"`
+	// ObjectKeys semua key di storage milik file ini (file asli dan rendition)
+	ObjectKeys []string  `json:"-"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// FileRendition versi gambar yang sudah diperkecil, Size adalah sisi terpanjang dalam pixel
+type FileRendition struct {
+	Size int    `json:"size"`
+	URI  string `json:"uri"`
 }
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+var ProductCategories = []string{"Food", "Beverage", "Clothes", "Furniture", "Tools"}
+
+type Product struct {
+	ProductId        uint      `json:"productId"`
+	Name             string    `json:"name"`
+	Category         string    `json:"category"`
+	Qty              int       `json:"qty"`
+	Price            int       `json:"price"`
+	Sku              string    `json:"sku"`
+	FileId           uint      `json:"fileId"`
+	FileUri          string    `json:"fileUri"`
+	FileThumbnailUri string    `json:"fileThumbnailUri"`
+	UserId           uint      `json:"-"`
+	Sold             int       `json:"sold"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+func IsValidProductCategory(category string) bool {
+	for _, c := range ProductCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const (
+	PurchaseStatusPending   = "pending"
+	PurchaseStatusPaid      = "paid"
+	PurchaseStatusCancelled = "cancelled"
+	PurchaseStatusExpired   = "expired"
+
+	ContactTypeEmail = "email"
+	ContactTypePhone = "phone"
+)
+
+type Purchase struct {
+	PurchaseId          uint            `json:"purchaseId"`
+	UserId              uint            `json:"-"`
+	SenderName          string          `json:"senderName"`
+	SenderContactType   string          `json:"senderContactType"`
+	SenderContactDetail string          `json:"senderContactDetail"`
+	Items               []PurchaseItem  `json:"purchasedItems"`
+	Payments            []PaymentDetail `json:"paymentDetails"`
+	TotalPrice          int             `json:"totalPrice"`
+	Status              string          `json:"status"`
+	CreatedAt           time.Time       `json:"createdAt"`
+	ExpiresAt           time.Time       `json:"expiresAt"`
+	PaidAt              *time.Time      `json:"paidAt"`
+	CancelledAt         *time.Time      `json:"cancelledAt"`
+}
+
+// PurchaseItem menyimpan snapshot product saat checkout, supaya perubahan product tidak mengubah riwayat
+type PurchaseItem struct {
+	ProductId        uint   `json:"productId"`
+	SellerId         uint   `json:"-"`
+	Name             string `json:"name"`
+	Category         string `json:"category"`
+	Sku              string `json:"sku"`
+	Price            int    `json:"price"`
+	Qty              int    `json:"qty"`
+	FileId           uint   `json:"fileId"`
+	FileUri          string `json:"fileUri"`
+	FileThumbnailUri string `json:"fileThumbnailUri"`
+}
+
+// PaymentDetail total yang harus ditransfer ke satu seller beserta bukti pembayarannya.
+// Rekening seller disalin saat checkout supaya order lama tidak ikut berubah kalau seller ganti rekening.
+type PaymentDetail struct {
+	SellerId          uint   `json:"-"`
+	BankAccountName   string `json:"bankAccountName"`
+	BankAccountHolder string `json:"bankAccountHolder"`
+	BankAccountNumber string `json:"bankAccountNumber"`
+	TotalPrice        int    `json:"totalPrice"`
+	FileId            *uint  `json:"fileId"`
+}
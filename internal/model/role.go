@@ -0,0 +1,36 @@
+package model
+
+const (
+	RoleBuyer  = "buyer"
+	RoleSeller = "seller"
+	RoleAdmin  = "admin"
+)
+
+// DefaultRoles role user baru, setiap user bisa membeli dan berjualan
+var DefaultRoles = []string{RoleBuyer, RoleSeller}
+
+const (
+	PermissionPurchaseCreate  = "purchase:create"
+	PermissionProductWrite    = "product:write"
+	PermissionUserManage      = "user:manage"
+	PermissionContentModerate = "content:moderate"
+)
+
+// RolePermissions permission yang dimiliki setiap role
+var RolePermissions = map[string][]string{
+	RoleBuyer:  {PermissionPurchaseCreate},
+	RoleSeller: {PermissionProductWrite},
+	RoleAdmin:  {PermissionUserManage, PermissionContentModerate},
+}
+
+// HasPermission true kalau salah satu role memiliki permission
+func HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, p := range RolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Session satu login (satu device), refresh token dirotasi setiap kali dipakai
+type Session struct {
+	ID     string `json:"id"`
+	UserId uint   `json:"-"`
+	// RefreshToken hanya terisi saat session dibuat atau dirotasi, yang disimpan di database hanya hash-nya
+	RefreshToken     string    `json:"-"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
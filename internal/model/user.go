@@ -1,15 +1,29 @@
 package model
 
+import "time"
+
 type User struct {
 	Id        uint    `json:"id"`
 	Email     *string `json:"email"`
 	Phone     *string `json:"phone"`
 	Password  string  `json:"-"`
 	CreatedAt string  `json:"createdAt"`
+	// Roles ikut disimpan di access token, lihat RolePermissions
+	Roles       []string   `json:"roles"`
+	SuspendedAt *time.Time `json:"suspendedAt"`
 }
 type UserProfile struct {
-	Id     uint    `json:"id"`
-	Email  *string `json:"email"`
-	Phone  *string `json:"phone"`
-	FileId uint    `json:"fileId"`
+	Id               uint    `json:"id"`
+	Email            *string `json:"email"`
+	Phone            *string `json:"phone"`
+	FileId           *uint   `json:"fileId"`
+	FileUri          *string `json:"fileUri"`
+	FileThumbnailUri *string `json:"fileThumbnailUri"`
+	EmailVerified    bool    `json:"emailVerified"`
+	PhoneVerified    bool    `json:"phoneVerified"`
+
+	// Rekening bank hanya dikirim ke pemilik profil dan ke buyer di response purchase
+	BankAccountName   *string `json:"bankAccountName"`
+	BankAccountHolder *string `json:"bankAccountHolder"`
+	BankAccountNumber *string `json:"bankAccountNumber"`
 }
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sprint3/pkg/config"
+)
+
+const (
+	DriverLog    = "log"
+	DriverFile   = "file"
+	DriverRemote = "remote"
+
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Message pesan ke user. To berisi alamat email atau nomor telepon sesuai Channel.
+type Message struct {
+	Channel string
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier abstraksi pengiriman pesan ke user (reset password, OTP, dll)
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New membuat notifier sesuai cfg.NotifierDriver
+func New(cfg *config.Config) (Notifier, error) {
+	switch cfg.NotifierDriver {
+	case DriverLog:
+		return LogNotifier{}, nil
+	case DriverFile:
+		return NewFileNotifier(cfg.NotifierFilePath), nil
+	case DriverRemote:
+		return NewRemoteNotifier(cfg)
+	default:
+		return nil, fmt.Errorf("unknown notifier driver %q", cfg.NotifierDriver)
+	}
+}
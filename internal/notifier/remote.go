@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sprint3/pkg/config"
+	"strings"
+	"time"
+)
+
+// RemoteNotifier mengirim email lewat SMTP dan SMS lewat webhook gateway SMS
+type RemoteNotifier struct {
+	smtpAddr string
+	smtpAuth smtp.Auth
+	from     string
+
+	smsWebhookURL string
+	client        *http.Client
+}
+
+func NewRemoteNotifier(cfg *config.Config) (*RemoteNotifier, error) {
+	if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+		return nil, errors.New("SMTP_HOST and SMTP_FROM are required for the remote notifier")
+	}
+	if cfg.SMSWebhookURL == "" {
+		return nil, errors.New("SMS_WEBHOOK_URL is required for the remote notifier")
+	}
+
+	n := &RemoteNotifier{
+		smtpAddr:      net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		from:          cfg.SMTPFrom,
+		smsWebhookURL: cfg.SMSWebhookURL,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+	if cfg.SMTPUsername != "" {
+		n.smtpAuth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return n, nil
+}
+
+func (n *RemoteNotifier) Send(ctx context.Context, msg Message) error {
+	switch msg.Channel {
+	case ChannelEmail:
+		return n.sendEmail(msg)
+	case ChannelSMS:
+		return n.sendSMS(ctx, msg)
+	default:
+		return fmt.Errorf("unknown notification channel %q", msg.Channel)
+	}
+}
+
+func (n *RemoteNotifier) sendEmail(msg Message) error {
+	// Header dari input user dibersihkan dari CR/LF supaya tidak bisa menyisipkan header lain
+	clean := strings.NewReplacer("\r", "", "\n", "")
+	body := "From: " + n.from + "\r\n" +
+		"To: " + clean.Replace(msg.To) + "\r\n" +
+		"Subject: " + clean.Replace(msg.Subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + msg.Body + "\r\n"
+
+	if err := smtp.SendMail(n.smtpAddr, n.smtpAuth, n.from, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
+
+func (n *RemoteNotifier) sendSMS(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]string{"to": msg.To, "message": msg.Body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.smsWebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SMS gateway returned %s", resp.Status)
+	}
+	return nil
+}
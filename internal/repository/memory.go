@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sprint3/internal/model"
+	"sync"
+	"time"
+)
+
+// NewMemory repository yang menyimpan data di memori, untuk test tanpa database.
+// Semua repository berbagi data supaya aturan antar tabel (profil ikut dibuat, file yang dipakai
+// profil tidak bisa dihapus, ganti password mencabut session) sama dengan Postgres.
+// Product dan purchase tidak ikut disimulasikan.
+func NewMemory() Repositories {
+	store := &memoryStore{
+		users:             map[uint]*memoryUser{},
+		profiles:          map[uint]*model.UserProfile{},
+		files:             map[uint]*model.File{},
+		sessions:          map[string]*memorySession{},
+		refreshTokens:     map[string]*memoryRefreshToken{},
+		revokedTokens:     map[string]time.Time{},
+		loginAttempts:     map[string]*memoryLoginAttempt{},
+		passwordResets:    map[string]*memoryPasswordReset{},
+		verificationCodes: map[verificationKey]*VerificationCode{},
+	}
+	return Repositories{
+		Users:          &memoryUsers{store},
+		Profiles:       &memoryProfiles{store},
+		Files:          &memoryFiles{store},
+		Sessions:       &memorySessions{store},
+		LoginAttempts:  &memoryLoginAttempts{store},
+		PasswordResets: &memoryPasswordResets{store},
+		Verifications:  &memoryVerifications{store},
+		Audit:          &memoryAudit{store},
+	}
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	users      map[uint]*memoryUser
+	profiles   map[uint]*model.UserProfile
+	files      map[uint]*model.File
+	nextUserID uint
+	nextFileID uint
+
+	sessions          map[string]*memorySession
+	refreshTokens     map[string]*memoryRefreshToken
+	revokedTokens     map[string]time.Time
+	loginAttempts     map[string]*memoryLoginAttempt
+	passwordResets    map[string]*memoryPasswordReset
+	verificationCodes map[verificationKey]*VerificationCode
+	audit             []AuditEntry
+}
+
+type memoryUser struct {
+	user          model.User
+	emailVerified bool
+	phoneVerified bool
+}
+
+// Data selalu disalin saat masuk dan keluar store, jadi perubahan oleh pemanggil tidak bocor ke store
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+func copyUser(user *model.User) *model.User {
+	c := *user
+	c.Email, c.Phone = copyString(user.Email), copyString(user.Phone)
+	c.Roles = append([]string(nil), user.Roles...)
+	return &c
+}
+
+func copyProfile(profile *model.UserProfile) *model.UserProfile {
+	c := *profile
+	c.Email, c.Phone = copyString(profile.Email), copyString(profile.Phone)
+	c.BankAccountName = copyString(profile.BankAccountName)
+	c.BankAccountHolder = copyString(profile.BankAccountHolder)
+	c.BankAccountNumber = copyString(profile.BankAccountNumber)
+	if profile.FileId != nil {
+		fileID := *profile.FileId
+		c.FileId = &fileID
+	}
+	return &c
+}
+
+func copyFile(file *model.File) *model.File {
+	c := *file
+	c.Renditions = append([]model.FileRendition(nil), file.Renditions...)
+	c.ObjectKeys = append([]string(nil), file.ObjectKeys...)
+	return &c
+}
+
+// contactOf pointer ke field email/phone milik user
+func contactOf(user *model.User, contact string) (**string, error) {
+	switch contact {
+	case ContactEmail:
+		return &user.Email, nil
+	case ContactPhone:
+		return &user.Phone, nil
+	}
+	return nil, fmt.Errorf("unknown contact %q", contact)
+}
+
+// findByContactLocked dipanggil dengan mu terkunci
+func (s *memoryStore) findByContactLocked(contact, value string) (*memoryUser, error) {
+	for _, u := range s.users {
+		field, err := contactOf(&u.user, contact)
+		if err != nil {
+			return nil, err
+		}
+		if *field != nil && **field == value {
+			return u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+type memoryUsers struct {
+	*memoryStore
+}
+
+func (r *memoryUsers) Create(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, contact := range []string{ContactEmail, ContactPhone} {
+		field, _ := contactOf(user, contact)
+		if *field == nil {
+			continue
+		}
+		if _, err := r.findByContactLocked(contact, **field); err == nil {
+			return ErrDuplicate
+		}
+	}
+
+	r.nextUserID++
+	user.Id = r.nextUserID
+	stored := copyUser(user)
+	stored.CreatedAt = time.Now().Format(time.RFC3339)
+	r.users[user.Id] = &memoryUser{user: *stored}
+	r.profiles[user.Id] = &model.UserProfile{Id: user.Id, Email: copyString(user.Email), Phone: copyString(user.Phone)}
+	return nil
+}
+
+func (r *memoryUsers) FindByID(ctx context.Context, userID uint) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyUser(&u.user), nil
+}
+
+func (r *memoryUsers) FindByContact(ctx context.Context, contact, value string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, err := r.findByContactLocked(contact, value)
+	if err != nil {
+		return nil, err
+	}
+	return copyUser(&u.user), nil
+}
+
+func (r *memoryUsers) AddContact(ctx context.Context, userID uint, contact, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	field, err := contactOf(&u.user, contact)
+	if err != nil {
+		return err
+	}
+	if *field != nil && **field != "" {
+		return ErrAlreadySet
+	}
+	if other, err := r.findByContactLocked(contact, value); err == nil && other.user.Id != userID {
+		return ErrDuplicate
+	} else if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	*field = copyString(&value)
+	profile := r.profiles[userID]
+	if contact == ContactEmail {
+		u.emailVerified = false
+		profile.Email = copyString(&value)
+	} else {
+		u.phoneVerified = false
+		profile.Phone = copyString(&value)
+	}
+	return nil
+}
+
+func (r *memoryUsers) UpdatePassword(ctx context.Context, userID uint, passwordHash, keepSessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	u.user.Password = passwordHash
+	r.revokeSessionsLocked(userID, keepSessionID)
+	return nil
+}
+
+type memoryProfiles struct {
+	*memoryStore
+}
+
+func (r *memoryProfiles) Get(ctx context.Context, userID uint) (*model.UserProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := copyProfile(profile)
+	c.EmailVerified = r.users[userID].emailVerified
+	c.PhoneVerified = r.users[userID].phoneVerified
+	return c, nil
+}
+
+func (r *memoryProfiles) Update(ctx context.Context, userID uint, update ProfileUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return ErrNotFound
+	}
+	if update.FileId != nil {
+		if _, ok := r.files[*update.FileId]; !ok {
+			return fmt.Errorf("file %d does not exist", *update.FileId)
+		}
+		fileID := *update.FileId
+		profile.FileId = &fileID
+	} else if update.ClearFileId {
+		profile.FileId = nil
+	}
+	if update.BankAccountName != nil || update.ClearBankAccountName {
+		profile.BankAccountName = copyString(update.BankAccountName)
+	}
+	if update.BankAccountHolder != nil || update.ClearBankAccountHolder {
+		profile.BankAccountHolder = copyString(update.BankAccountHolder)
+	}
+	if update.BankAccountNumber != nil || update.ClearBankAccountNumber {
+		profile.BankAccountNumber = copyString(update.BankAccountNumber)
+	}
+	return nil
+}
+
+type memoryFiles struct {
+	*memoryStore
+}
+
+func (r *memoryFiles) Create(ctx context.Context, file *model.File) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextFileID++
+	file.ID = int(r.nextFileID)
+	r.files[r.nextFileID] = copyFile(file)
+	return nil
+}
+
+func (r *memoryFiles) FindByID(ctx context.Context, fileID uint) (*model.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[fileID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyFile(file), nil
+}
+
+func (r *memoryFiles) ListByUser(ctx context.Context, userID uint, limit, offset int) ([]model.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files := []model.File{}
+	for _, file := range r.files {
+		if file.UserId == userID {
+			files = append(files, *copyFile(file))
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].CreatedAt.Equal(files[j].CreatedAt) {
+			return files[i].CreatedAt.After(files[j].CreatedAt)
+		}
+		return files[i].ID > files[j].ID
+	})
+
+	if offset >= len(files) {
+		return []model.File{}, nil
+	}
+	files = files[offset:]
+	if len(files) > limit {
+		files = files[:limit]
+	}
+	return files, nil
+}
+
+func (r *memoryFiles) Delete(ctx context.Context, fileID, userID uint) (*model.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[fileID]
+	if !ok || file.UserId != userID {
+		return nil, ErrNotFound
+	}
+	for _, profile := range r.profiles {
+		if profile.FileId != nil && *profile.FileId == fileID {
+			return nil, ErrInUse
+		}
+	}
+	delete(r.files, fileID)
+	return file, nil
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"sprint3/internal/model"
+	"time"
+)
+
+type memorySession struct {
+	userID  uint
+	revoked bool
+}
+
+type memoryRefreshToken struct {
+	sessionID string
+	expiresAt time.Time
+	used      bool
+}
+
+type memoryLoginAttempt struct {
+	failures     int64
+	lastFailedAt time.Time
+	lockedUntil  *time.Time
+}
+
+type memoryPasswordReset struct {
+	userID    uint
+	expiresAt time.Time
+	createdAt time.Time
+}
+
+type verificationKey struct {
+	userID  uint
+	channel string
+}
+
+// revokeSessionsLocked dipanggil dengan mu terkunci
+func (s *memoryStore) revokeSessionsLocked(userID uint, keepSessionID string) int64 {
+	var revoked int64
+	for id, session := range s.sessions {
+		if session.userID == userID && !session.revoked && id != keepSessionID {
+			session.revoked = true
+			revoked++
+		}
+	}
+	return revoked
+}
+
+type memorySessions struct {
+	*memoryStore
+}
+
+func (r *memorySessions) Create(ctx context.Context, session *model.Session, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID] = &memorySession{userID: session.UserId}
+	r.refreshTokens[tokenHash] = &memoryRefreshToken{sessionID: session.ID, expiresAt: session.RefreshExpiresAt}
+	return nil
+}
+
+func (r *memorySessions) Rotate(ctx context.Context, tokenHash, newHash string, newExpiresAt time.Time) (*model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[tokenHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	session := r.sessions[token.sessionID]
+	if session.revoked || time.Now().After(token.expiresAt) {
+		return nil, ErrNotFound
+	}
+	if token.used {
+		session.revoked = true
+		return nil, ErrReused
+	}
+
+	token.used = true
+	r.refreshTokens[newHash] = &memoryRefreshToken{sessionID: token.sessionID, expiresAt: newExpiresAt}
+	return &model.Session{ID: token.sessionID, UserId: session.userID, RefreshExpiresAt: newExpiresAt}, nil
+}
+
+func (r *memorySessions) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[sessionID]; ok && session.userID == userID {
+		session.revoked = true
+	}
+	return nil
+}
+
+func (r *memorySessions) RevokeAll(ctx context.Context, userID uint, exceptSessionID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.revokeSessionsLocked(userID, exceptSessionID), nil
+}
+
+func (r *memorySessions) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedTokens[jti] = expiresAt
+	return nil
+}
+
+func (r *memorySessions) IsActive(ctx context.Context, sessionID, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[sessionID]
+	if !ok || session.revoked {
+		return false, nil
+	}
+	_, revoked := r.revokedTokens[jti]
+	return !revoked, nil
+}
+
+type memoryLoginAttempts struct {
+	*memoryStore
+}
+
+func (r *memoryLoginAttempts) LockedUntil(ctx context.Context, keys []string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var lockedUntil *time.Time
+	for _, key := range keys {
+		attempt, ok := r.loginAttempts[key]
+		if !ok || attempt.lockedUntil == nil || !attempt.lockedUntil.After(time.Now()) {
+			continue
+		}
+		if lockedUntil == nil || attempt.lockedUntil.After(*lockedUntil) {
+			until := *attempt.lockedUntil
+			lockedUntil = &until
+		}
+	}
+	return lockedUntil, nil
+}
+
+func (r *memoryLoginAttempts) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	attempt, ok := r.loginAttempts[key]
+	if !ok {
+		r.loginAttempts[key] = &memoryLoginAttempt{failures: 1, lastFailedAt: now}
+		return 1, nil
+	}
+
+	last := attempt.lastFailedAt
+	if attempt.lockedUntil != nil && attempt.lockedUntil.After(last) {
+		last = *attempt.lockedUntil
+	}
+	if last.Before(now.Add(-window)) {
+		attempt.failures = 1
+	} else {
+		attempt.failures++
+	}
+	attempt.lastFailedAt = now
+	return attempt.failures, nil
+}
+
+func (r *memoryLoginAttempts) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.loginAttempts[key]; ok {
+		attempt.lockedUntil = &until
+	}
+	return nil
+}
+
+func (r *memoryLoginAttempts) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.loginAttempts, key)
+	return nil
+}
+
+func (r *memoryLoginAttempts) Prune(ctx context.Context, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := time.Now().Add(-window)
+	var pruned int64
+	for key, attempt := range r.loginAttempts {
+		last := attempt.lastFailedAt
+		if attempt.lockedUntil != nil && attempt.lockedUntil.After(last) {
+			last = *attempt.lockedUntil
+		}
+		if last.Before(cutoff) {
+			delete(r.loginAttempts, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+type memoryPasswordResets struct {
+	*memoryStore
+}
+
+func (r *memoryPasswordResets) Replace(ctx context.Context, userID uint, tokenHash string, expiresAt, notBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, reset := range r.passwordResets {
+		if reset.userID == userID && reset.createdAt.After(notBefore) {
+			return ErrTooSoon
+		}
+	}
+	for hash, reset := range r.passwordResets {
+		if reset.userID == userID {
+			delete(r.passwordResets, hash)
+		}
+	}
+	r.passwordResets[tokenHash] = &memoryPasswordReset{userID: userID, expiresAt: expiresAt, createdAt: time.Now()}
+	return nil
+}
+
+func (r *memoryPasswordResets) Consume(ctx context.Context, tokenHash string) (uint, time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reset, ok := r.passwordResets[tokenHash]
+	if !ok {
+		return 0, time.Time{}, ErrNotFound
+	}
+	delete(r.passwordResets, tokenHash)
+	return reset.userID, reset.expiresAt, nil
+}
+
+type memoryVerifications struct {
+	*memoryStore
+}
+
+func (r *memoryVerifications) Save(ctx context.Context, code *VerificationCode, notBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := verificationKey{code.UserId, code.Channel}
+	if existing, ok := r.verificationCodes[key]; ok && existing.CreatedAt.After(notBefore) {
+		return ErrTooSoon
+	}
+	stored := *code
+	stored.Attempts = 0
+	r.verificationCodes[key] = &stored
+	return nil
+}
+
+func (r *memoryVerifications) Find(ctx context.Context, userID uint, channel string) (*VerificationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.verificationCodes[verificationKey{userID, channel}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *code
+	return &c, nil
+}
+
+func (r *memoryVerifications) ClaimAttempt(ctx context.Context, userID uint, channel string, maxAttempts int64) (*VerificationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.verificationCodes[verificationKey{userID, channel}]
+	if !ok || code.Attempts >= maxAttempts || !time.Now().Before(code.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	code.Attempts++
+	c := *code
+	return &c, nil
+}
+
+func (r *memoryVerifications) Confirm(ctx context.Context, code *VerificationCode, contact string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[code.UserId]
+	if !ok {
+		return ErrNotFound
+	}
+	field, err := contactOf(&u.user, contact)
+	if err != nil {
+		return err
+	}
+	if *field == nil || **field != code.Destination {
+		return ErrNotFound
+	}
+
+	if contact == ContactEmail {
+		u.emailVerified = true
+	} else {
+		u.phoneVerified = true
+	}
+	delete(r.verificationCodes, verificationKey{code.UserId, code.Channel})
+	return nil
+}
+
+type memoryAudit struct {
+	*memoryStore
+}
+
+func (r *memoryAudit) Record(ctx context.Context, entry AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.audit = append(r.audit, entry)
+	return nil
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sprint3/internal/model"
+	"time"
+)
+
+// NewPostgres repository yang menyimpan data di Postgres lewat pool db
+func NewPostgres(db *pgxpool.Pool) Repositories {
+	return Repositories{
+		Users:          &postgresUsers{db: db},
+		Profiles:       &postgresProfiles{db: db},
+		Files:          &postgresFiles{db: db},
+		Sessions:       &postgresSessions{db: db},
+		LoginAttempts:  &postgresLoginAttempts{db: db},
+		PasswordResets: &postgresPasswordResets{db: db},
+		Verifications:  &postgresVerifications{db: db},
+		Audit:          &postgresAudit{db: db},
+	}
+}
+
+// contactColumns kolom per kontak, nama kolom tidak pernah diambil langsung dari input
+var contactColumns = map[string]struct{ contact, verifiedAt string }{
+	ContactEmail: {`email`, `"emailVerifiedAt"`},
+	ContactPhone: {`phone`, `"phoneVerifiedAt"`},
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+type postgresUsers struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresUsers) Create(ctx context.Context, user *model.User) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO public.user (email, phone, password, roles, "createdAt")
+         VALUES ($1, $2, $3, $4, $5)
+         RETURNING "userId"`,
+		user.Email, user.Phone, user.Password, user.Roles, time.Now(),
+	).Scan(&user.Id)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	} else if err != nil {
+		return fmt.Errorf("failed to register user: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO "userProfile" ("userId", email, phone) VALUES ($1, $2, $3)`,
+		user.Id, user.Email, user.Phone)
+	if err != nil {
+		return fmt.Errorf("failed to insert user profile: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+const selectUserQuery = `SELECT "userId", email, phone, password, "createdAt"::text, roles, "suspendedAt" FROM public.user`
+
+func (r *postgresUsers) FindByID(ctx context.Context, userID uint) (*model.User, error) {
+	return scanUser(r.db.QueryRow(ctx, selectUserQuery+` WHERE "userId" = $1`, userID))
+}
+
+func (r *postgresUsers) FindByContact(ctx context.Context, contact, value string) (*model.User, error) {
+	columns, ok := contactColumns[contact]
+	if !ok {
+		return nil, fmt.Errorf("unknown contact %q", contact)
+	}
+	return scanUser(r.db.QueryRow(ctx, selectUserQuery+` WHERE `+columns.contact+` = $1`, value))
+}
+
+func (r *postgresUsers) AddContact(ctx context.Context, userID uint, contact, value string) error {
+	columns, ok := contactColumns[contact]
+	if !ok {
+		return fmt.Errorf("unknown contact %q", contact)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Kontak yang sudah ada tidak pernah ditimpa, jadi token yang bocor tidak bisa dipakai mengambil alih akun
+	var current *string
+	err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT %s FROM public.user WHERE "userId" = $1 FOR UPDATE`, columns.contact), userID).
+		Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if current != nil && *current != "" {
+		return ErrAlreadySet
+	}
+
+	_, err = tx.Exec(ctx,
+		fmt.Sprintf(`UPDATE public.user SET %s = $1, %s = NULL WHERE "userId" = $2`, columns.contact, columns.verifiedAt),
+		value, userID)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	} else if err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE "userProfile" SET %s = $1 WHERE "userId" = $2`, columns.contact), value, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user profile: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresUsers) UpdatePassword(ctx context.Context, userID uint, passwordHash, keepSessionID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE public.user SET password = $1 WHERE "userId" = $2`, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if _, err := revokeSessions(ctx, tx, userID, keepSessionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+func scanUser(row pgx.Row) (*model.User, error) {
+	var user model.User
+	err := row.Scan(&user.Id, &user.Email, &user.Phone, &user.Password, &user.CreatedAt, &user.Roles, &user.SuspendedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return &user, nil
+}
+
+type postgresProfiles struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresProfiles) Get(ctx context.Context, userID uint) (*model.UserProfile, error) {
+	var profile model.UserProfile
+	err := r.db.QueryRow(ctx,
+		`SELECT p."userId", p.email, p.phone, p."fileId", p."bankAccountName", p."bankAccountHolder", p."bankAccountNumber",
+                u."emailVerifiedAt" IS NOT NULL, u."phoneVerifiedAt" IS NOT NULL
+         FROM "userProfile" p
+         JOIN public.user u ON u."userId" = p."userId"
+         WHERE p."userId" = $1`,
+		userID,
+	).Scan(&profile.Id, &profile.Email, &profile.Phone, &profile.FileId, &profile.BankAccountName,
+		&profile.BankAccountHolder, &profile.BankAccountNumber, &profile.EmailVerified, &profile.PhoneVerified)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return &profile, nil
+}
+
+func (r *postgresProfiles) Update(ctx context.Context, userID uint, update ProfileUpdate) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE "userProfile" SET
+             "fileId" = CASE WHEN $5 THEN NULL ELSE COALESCE($1, "fileId") END,
+             "bankAccountName" = CASE WHEN $6 THEN NULL ELSE COALESCE($2, "bankAccountName") END,
+             "bankAccountHolder" = CASE WHEN $7 THEN NULL ELSE COALESCE($3, "bankAccountHolder") END,
+             "bankAccountNumber" = CASE WHEN $8 THEN NULL ELSE COALESCE($4, "bankAccountNumber") END
+         WHERE "userId" = $9`,
+		update.FileId, update.BankAccountName, update.BankAccountHolder, update.BankAccountNumber,
+		update.ClearFileId && update.FileId == nil,
+		update.ClearBankAccountName && update.BankAccountName == nil,
+		update.ClearBankAccountHolder && update.BankAccountHolder == nil,
+		update.ClearBankAccountNumber && update.BankAccountNumber == nil,
+		userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user profile: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type postgresFiles struct {
+	db *pgxpool.Pool
+}
+
+const selectFileQuery = `SELECT "fileId", "userId", "fileUri", "fileThumbnailUri", COALESCE(renditions, '[]'::jsonb),
+	COALESCE("objectKeys", '{}'), visibility, "createdAt"
+	FROM file`
+
+func (r *postgresFiles) Create(ctx context.Context, file *model.File) error {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO file ("userId", "fileUri", "fileThumbnailUri", renditions, "objectKeys", visibility, "createdAt")
+         VALUES ($1, $2, $3, $4, $5, $6, $7)
+         RETURNING "fileId"`,
+		file.UserId, file.URI, file.ThumbnailURI, file.Renditions, file.ObjectKeys, file.Visibility, file.CreatedAt,
+	).Scan(&file.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert file: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresFiles) FindByID(ctx context.Context, fileID uint) (*model.File, error) {
+	return scanFile(r.db.QueryRow(ctx, selectFileQuery+` WHERE "fileId" = $1`, fileID))
+}
+
+func (r *postgresFiles) ListByUser(ctx context.Context, userID uint, limit, offset int) ([]model.File, error) {
+	rows, err := r.db.Query(ctx,
+		selectFileQuery+` WHERE "userId" = $1 ORDER BY "createdAt" DESC, "fileId" DESC LIMIT $2 OFFSET $3`,
+		userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer rows.Close()
+
+	files := []model.File{}
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return files, nil
+}
+
+func (r *postgresFiles) Delete(ctx context.Context, fileID, userID uint) (*model.File, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	file, err := scanFile(tx.QueryRow(ctx,
+		selectFileQuery+` WHERE "fileId" = $1 AND "userId" = $2 FOR UPDATE`, fileID, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	var inUse bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM "userProfile" WHERE "fileId" = $1)
+             OR EXISTS(SELECT 1 FROM product WHERE "fileId" = $1)
+             OR EXISTS(SELECT 1 FROM "purchaseItem" WHERE "fileId" = $1)
+             OR EXISTS(SELECT 1 FROM "purchasePayment" WHERE "fileId" = $1)`,
+		fileID,
+	).Scan(&inUse)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	if inUse {
+		return nil, ErrInUse
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM file WHERE "fileId" = $1`, fileID); err != nil {
+		return nil, fmt.Errorf("failed to delete file: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return file, nil
+}
+
+func scanFile(row pgx.Row) (*model.File, error) {
+	var file model.File
+	err := row.Scan(&file.ID, &file.UserId, &file.URI, &file.ThumbnailURI, &file.Renditions, &file.ObjectKeys,
+		&file.Visibility, &file.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return &file, nil
+}
@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sprint3/internal/model"
+	"time"
+)
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// revokeSessions mencabut session aktif userID kecuali keepSessionID, refresh token-nya otomatis ikut tidak berlaku
+func revokeSessions(ctx context.Context, q execer, userID uint, keepSessionID string) (int64, error) {
+	tag, err := q.Exec(ctx,
+		`UPDATE "userSession" SET "revokedAt" = now()
+         WHERE "revokedAt" IS NULL AND "userId" = $1 AND "sessionId" <> $2`,
+		userID, keepSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke session: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+type postgresSessions struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresSessions) Create(ctx context.Context, session *model.Session, tokenHash string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO "userSession" ("sessionId", "userId", "createdAt") VALUES ($1, $2, $3)`,
+		session.ID, session.UserId, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+	if err := insertRefreshToken(ctx, tx, session.ID, tokenHash, session.RefreshExpiresAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresSessions) Rotate(ctx context.Context, tokenHash, newHash string, newExpiresAt time.Time) (*model.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	session := &model.Session{}
+	var expiresAt time.Time
+	var usedAt, revokedAt *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT s."sessionId", s."userId", s."revokedAt", r."expiresAt", r."usedAt"
+         FROM "refreshToken" r
+         JOIN "userSession" s ON s."sessionId" = r."sessionId"
+         WHERE r."tokenHash" = $1
+         FOR UPDATE OF r, s`,
+		tokenHash,
+	).Scan(&session.ID, &session.UserId, &revokedAt, &expiresAt, &usedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+
+	if revokedAt != nil || time.Now().After(expiresAt) {
+		return nil, ErrNotFound
+	}
+	if usedAt != nil {
+		_, err := tx.Exec(ctx, `UPDATE "userSession" SET "revokedAt" = now() WHERE "sessionId" = $1`, session.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %v", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %v", err)
+		}
+		return nil, ErrReused
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE "refreshToken" SET "usedAt" = $1 WHERE "tokenHash" = $2`, time.Now(), tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update refresh token: %v", err)
+	}
+	if err := insertRefreshToken(ctx, tx, session.ID, newHash, newExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	session.RefreshExpiresAt = newExpiresAt
+	return session, nil
+}
+
+func insertRefreshToken(ctx context.Context, q execer, sessionID, tokenHash string, expiresAt time.Time) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO "refreshToken" ("tokenHash", "sessionId", "expiresAt", "createdAt") VALUES ($1, $2, $3, $4)`,
+		tokenHash, sessionID, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresSessions) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE "userSession" SET "revokedAt" = now() WHERE "revokedAt" IS NULL AND "sessionId" = $1 AND "userId" = $2`,
+		sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresSessions) RevokeAll(ctx context.Context, userID uint, exceptSessionID string) (int64, error) {
+	return revokeSessions(ctx, r.db, userID, exceptSessionID)
+}
+
+// RevokeToken ikut membersihkan jti yang access token-nya sudah kedaluwarsa
+func (r *postgresSessions) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM "revokedToken" WHERE "expiresAt" < now()`); err != nil {
+		return fmt.Errorf("failed to clean revoked tokens: %v", err)
+	}
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO "revokedToken" (jti, "expiresAt") VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresSessions) IsActive(ctx context.Context, sessionID, jti string) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM "userSession" WHERE "sessionId" = $1 AND "revokedAt" IS NULL)
+            AND NOT EXISTS(SELECT 1 FROM "revokedToken" WHERE jti = $2)`,
+		sessionID, jti,
+	).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return active, nil
+}
+
+type postgresLoginAttempts struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresLoginAttempts) LockedUntil(ctx context.Context, keys []string) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.QueryRow(ctx,
+		`SELECT max("lockedUntil") FROM "loginAttempt" WHERE key = ANY($1) AND "lockedUntil" > now()`, keys,
+	).Scan(&lockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return lockedUntil, nil
+}
+
+func (r *postgresLoginAttempts) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	var failures int64
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO "loginAttempt" (key, failures, "lastFailedAt") VALUES ($1, 1, now())
+         ON CONFLICT (key) DO UPDATE
+         SET failures = CASE
+                 WHEN GREATEST("loginAttempt"."lastFailedAt", "loginAttempt"."lockedUntil") < now() - $2 * interval '1 second'
+                 THEN 1 ELSE "loginAttempt".failures + 1 END,
+             "lastFailedAt" = now()
+         RETURNING failures`,
+		key, int64(window.Seconds()),
+	).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login attempt: %v", err)
+	}
+	return failures, nil
+}
+
+func (r *postgresLoginAttempts) Lock(ctx context.Context, key string, until time.Time) error {
+	if _, err := r.db.Exec(ctx, `UPDATE "loginAttempt" SET "lockedUntil" = $1 WHERE key = $2`, until, key); err != nil {
+		return fmt.Errorf("failed to lock login: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresLoginAttempts) Reset(ctx context.Context, key string) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM "loginAttempt" WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresLoginAttempts) Prune(ctx context.Context, window time.Duration) (int64, error) {
+	// GREATEST mengabaikan NULL, jadi key yang tidak pernah dikunci dinilai dari "lastFailedAt" saja
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM "loginAttempt" WHERE GREATEST("lastFailedAt", "lockedUntil") < now() - $1 * interval '1 second'`,
+		int64(window.Seconds()),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune login attempts: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+type postgresPasswordResets struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresPasswordResets) Replace(ctx context.Context, userID uint, tokenHash string, expiresAt, notBefore time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Baris user dikunci supaya dua permintaan bersamaan untuk user yang sama dicek bergantian
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM public.user WHERE "userId" = $1 FOR UPDATE`, userID); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	var recent bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM "passwordReset" WHERE "userId" = $1 AND "createdAt" > $2)`,
+		userID, notBefore).Scan(&recent)
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if recent {
+		return ErrTooSoon
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM "passwordReset" WHERE "userId" = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear reset tokens: %v", err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO "passwordReset" ("tokenHash", "userId", "expiresAt", "createdAt") VALUES ($1, $2, $3, $4)`,
+		tokenHash, userID, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store reset token: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+func (r *postgresPasswordResets) Consume(ctx context.Context, tokenHash string) (uint, time.Time, error) {
+	var userID uint
+	var expiresAt time.Time
+	err := r.db.QueryRow(ctx, `DELETE FROM "passwordReset" WHERE "tokenHash" = $1 RETURNING "userId", "expiresAt"`,
+		tokenHash).Scan(&userID, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, time.Time{}, ErrNotFound
+	} else if err != nil {
+		return 0, time.Time{}, fmt.Errorf("database error: %v", err)
+	}
+	return userID, expiresAt, nil
+}
+
+type postgresVerifications struct {
+	db *pgxpool.Pool
+}
+
+// Save memakai upsert bersyarat, jadi dua permintaan kirim ulang bersamaan hanya satu yang berhasil
+func (r *postgresVerifications) Save(ctx context.Context, code *VerificationCode, notBefore time.Time) error {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO "verificationCode" ("userId", channel, destination, "codeHash", attempts, "expiresAt", "createdAt")
+         VALUES ($1, $2, $3, $4, 0, $5, $6)
+         ON CONFLICT ("userId", channel) DO UPDATE
+         SET destination = EXCLUDED.destination, "codeHash" = EXCLUDED."codeHash", attempts = 0,
+             "expiresAt" = EXCLUDED."expiresAt", "createdAt" = EXCLUDED."createdAt"
+         WHERE "verificationCode"."createdAt" <= $7`,
+		code.UserId, code.Channel, code.Destination, code.CodeHash, code.ExpiresAt, code.CreatedAt, notBefore)
+	if err != nil {
+		return fmt.Errorf("failed to store verification code: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTooSoon
+	}
+	return nil
+}
+
+func (r *postgresVerifications) Find(ctx context.Context, userID uint, channel string) (*VerificationCode, error) {
+	code := &VerificationCode{UserId: userID, Channel: channel}
+	err := r.db.QueryRow(ctx,
+		`SELECT destination, "codeHash", attempts, "expiresAt", "createdAt" FROM "verificationCode"
+         WHERE "userId" = $1 AND channel = $2`,
+		userID, channel,
+	).Scan(&code.Destination, &code.CodeHash, &code.Attempts, &code.ExpiresAt, &code.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return code, nil
+}
+
+// ClaimAttempt cek batas dan penambahan percobaan dalam satu UPDATE, jadi dikunci per baris oleh database
+func (r *postgresVerifications) ClaimAttempt(ctx context.Context, userID uint, channel string, maxAttempts int64) (*VerificationCode, error) {
+	code := &VerificationCode{UserId: userID, Channel: channel}
+	err := r.db.QueryRow(ctx,
+		`UPDATE "verificationCode" SET attempts = attempts + 1
+         WHERE "userId" = $1 AND channel = $2 AND attempts < $3 AND "expiresAt" > now()
+         RETURNING destination, "codeHash", attempts, "expiresAt", "createdAt"`,
+		userID, channel, maxAttempts,
+	).Scan(&code.Destination, &code.CodeHash, &code.Attempts, &code.ExpiresAt, &code.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to record attempt: %v", err)
+	}
+	return code, nil
+}
+
+func (r *postgresVerifications) Confirm(ctx context.Context, code *VerificationCode, contact string) error {
+	columns, ok := contactColumns[contact]
+	if !ok {
+		return fmt.Errorf("unknown contact %q", contact)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		fmt.Sprintf(`UPDATE public.user SET %s = now() WHERE "userId" = $1 AND %s = $2`, columns.verifiedAt, columns.contact),
+		code.UserId, code.Destination)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM "verificationCode" WHERE "userId" = $1 AND channel = $2`, code.UserId, code.Channel)
+	if err != nil {
+		return fmt.Errorf("failed to delete verification code: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+type postgresAudit struct {
+	db *pgxpool.Pool
+}
+
+func (r *postgresAudit) Record(ctx context.Context, entry AuditEntry) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO "auditLog" ("userId", event, ip, detail, "createdAt") VALUES ($1, $2, $3, $4, $5)`,
+		entry.UserId, entry.Event, entry.IP, entry.Detail, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record audit log: %v", err)
+	}
+	return nil
+}
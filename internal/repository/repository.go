@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sprint3/internal/model"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("record not found")
+	ErrDuplicate  = errors.New("record already exists")
+	ErrInUse      = errors.New("record is still referenced")
+	ErrReused     = errors.New("record already used")
+	ErrTooSoon    = errors.New("record was replaced too recently")
+	ErrAlreadySet = errors.New("field is already set")
+)
+
+// Kontak yang bisa dipakai login, sekaligus nama kolomnya di public.user dan "userProfile"
+const (
+	ContactEmail = "email"
+	ContactPhone = "phone"
+)
+
+// UserRepository akun login (public.user)
+type UserRepository interface {
+	// Create menyimpan user baru beserta profil kosongnya lalu mengisi user.Id.
+	// ErrDuplicate kalau email/phone sudah dipakai.
+	Create(ctx context.Context, user *model.User) error
+	FindByID(ctx context.Context, userID uint) (*model.User, error)
+	// FindByContact mencari user dengan email atau phone, contact diisi ContactEmail/ContactPhone
+	FindByContact(ctx context.Context, contact, value string) (*model.User, error)
+	// AddContact mengisi email/phone user dan profilnya yang masih kosong, kontak baru belum terverifikasi.
+	// ErrAlreadySet kalau kontak itu sudah terisi, ErrDuplicate kalau sudah dipakai user lain.
+	AddContact(ctx context.Context, userID uint, contact, value string) error
+	// UpdatePassword mengganti hash password dan mencabut semua session user kecuali keepSessionID
+	// ("" berarti semua) dalam satu transaksi
+	UpdatePassword(ctx context.Context, userID uint, passwordHash, keepSessionID string) error
+}
+
+// ProfileUpdate field profil yang ingin diubah, field bernilai nil tidak diubah.
+// Clear* mengosongkan field-nya (null di PATCH /v1/user) dan hanya dipakai kalau field tersebut nil.
+type ProfileUpdate struct {
+	FileId            *uint
+	BankAccountName   *string
+	BankAccountHolder *string
+	BankAccountNumber *string
+
+	ClearFileId            bool
+	ClearBankAccountName   bool
+	ClearBankAccountHolder bool
+	ClearBankAccountNumber bool
+}
+
+// ProfileRepository profil user ("userProfile")
+type ProfileRepository interface {
+	// Get mengembalikan profil beserta status verifikasi kontak. URL foto profil tidak diisi,
+	// service mengambilnya dari FileRepository supaya file private bisa diberi signed URL.
+	Get(ctx context.Context, userID uint) (*model.UserProfile, error)
+	Update(ctx context.Context, userID uint, update ProfileUpdate) error
+}
+
+// FileRepository metadata file yang sudah diunggah (file)
+type FileRepository interface {
+	// Create menyimpan file lalu mengisi file.ID
+	Create(ctx context.Context, file *model.File) error
+	FindByID(ctx context.Context, fileID uint) (*model.File, error)
+	// ListByUser file milik userID, terbaru lebih dulu
+	ListByUser(ctx context.Context, userID uint, limit, offset int) ([]model.File, error)
+	// Delete menghapus file milik userID dan mengembalikan datanya supaya object di storage bisa ikut dihapus.
+	// ErrInUse kalau file masih dipakai profil, product atau purchase.
+	Delete(ctx context.Context, fileID, userID uint) (*model.File, error)
+}
+
+// SessionRepository session login ("userSession"), refresh token-nya ("refreshToken") dan jti access token
+// yang dicabut ("revokedToken"). Refresh token hanya disimpan sebagai hash.
+type SessionRepository interface {
+	// Create menyimpan session beserta refresh token pertamanya yang berlaku sampai session.RefreshExpiresAt
+	Create(ctx context.Context, session *model.Session, tokenHash string) error
+	// Rotate menandai refresh token tokenHash terpakai lalu menyimpan newHash di session yang sama.
+	// ErrNotFound kalau token tidak ada, kedaluwarsa atau session-nya sudah dicabut.
+	// ErrReused kalau token sudah pernah dipakai, session-nya ikut dicabut.
+	Rotate(ctx context.Context, tokenHash, newHash string, newExpiresAt time.Time) (*model.Session, error)
+	// Revoke mencabut satu session milik userID
+	Revoke(ctx context.Context, userID uint, sessionID string) error
+	// RevokeAll mencabut semua session userID kecuali exceptSessionID ("" berarti semua) dan mengembalikan jumlahnya
+	RevokeAll(ctx context.Context, userID uint, exceptSessionID string) (int64, error)
+	// RevokeToken mencatat jti access token yang dicabut sampai token itu kedaluwarsa
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// IsActive true kalau session belum dicabut dan jti belum dicabut
+	IsActive(ctx context.Context, sessionID, jti string) (bool, error)
+}
+
+// LoginAttemptRepository hitungan login gagal per key akun atau IP ("loginAttempt")
+type LoginAttemptRepository interface {
+	// LockedUntil akhir lockout terlama dari keys yang masih berlaku, nil kalau tidak ada yang terkunci
+	LockedUntil(ctx context.Context, keys []string) (*time.Time, error)
+	// RecordFailure menambah hitungan gagal key dan mengembalikan jumlahnya. Hitungan dimulai lagi dari 1
+	// kalau gagal terakhir (atau akhir lockout) sudah di luar window.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+	// Prune menghapus hitungan yang gagal terakhir dan akhir lockout-nya sudah di luar window,
+	// sama artinya dengan belum pernah gagal. Mengembalikan jumlah key yang dihapus.
+	Prune(ctx context.Context, window time.Duration) (int64, error)
+}
+
+// PasswordResetRepository token reset password ("passwordReset"), disimpan sebagai hash
+type PasswordResetRepository interface {
+	// Replace menyimpan token baru untuk userID, token lama tidak berlaku lagi.
+	// ErrTooSoon kalau token sebelumnya dibuat setelah notBefore.
+	Replace(ctx context.Context, userID uint, tokenHash string, expiresAt, notBefore time.Time) error
+	// Consume menghapus token lalu mengembalikan pemilik dan masa berlakunya, ErrNotFound kalau tidak ada
+	Consume(ctx context.Context, tokenHash string) (uint, time.Time, error)
+}
+
+// VerificationCode OTP terakhir untuk satu kontak user, kodenya disimpan sebagai hash
+type VerificationCode struct {
+	UserId      uint
+	Channel     string
+	Destination string
+	CodeHash    string
+	Attempts    int64
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+// VerificationRepository OTP verifikasi kontak ("verificationCode")
+type VerificationRepository interface {
+	// Save mengganti kode userID/channel dengan percobaan direset.
+	// ErrTooSoon kalau kode sebelumnya dibuat setelah notBefore.
+	Save(ctx context.Context, code *VerificationCode, notBefore time.Time) error
+	Find(ctx context.Context, userID uint, channel string) (*VerificationCode, error)
+	// ClaimAttempt menghitung satu percobaan lalu mengembalikan kodenya, sebelum kode dicocokkan.
+	// ErrNotFound kalau tidak ada kode yang belum kedaluwarsa dengan percobaan di bawah maxAttempts,
+	// jadi percobaan bersamaan tidak bisa melewati batas.
+	ClaimAttempt(ctx context.Context, userID uint, channel string, maxAttempts int64) (*VerificationCode, error)
+	// Confirm menandai kontak user terverifikasi kalau isinya masih code.Destination, lalu menghapus kodenya.
+	// contact diisi ContactEmail/ContactPhone. ErrNotFound kalau kontaknya sudah diganti.
+	Confirm(ctx context.Context, code *VerificationCode, contact string) error
+}
+
+// AuditEntry satu kejadian di "auditLog", UserId nil kalau tidak terkait akun tertentu
+type AuditEntry struct {
+	UserId *uint
+	Event  string
+	IP     string
+	Detail map[string]interface{}
+}
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// Repositories semua repository yang dipakai service
+type Repositories struct {
+	Users          UserRepository
+	Profiles       ProfileRepository
+	Files          FileRepository
+	Sessions       SessionRepository
+	LoginAttempts  LoginAttemptRepository
+	PasswordResets PasswordResetRepository
+	Verifications  VerificationRepository
+	Audit          AuditRepository
+}
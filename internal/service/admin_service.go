@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"sprint3/internal/model"
+)
+
+var ErrCannotModerateSelf = errors.New("admin cannot suspend or delete own account")
+
+// ListUsers daftar semua user untuk admin, terbaru lebih dulu
+func (s *Service) ListUsers(limit, offset int) ([]model.User, error) {
+	rows, err := s.db.Query(context.Background(),
+		`SELECT "userId", email, phone, "createdAt"::text, roles, "suspendedAt" FROM public.user
+         ORDER BY "userId" DESC LIMIT $1 OFFSET $2`,
+		limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.Id, &user.Email, &user.Phone, &user.CreatedAt, &user.Roles, &user.SuspendedAt); err != nil {
+			return nil, fmt.Errorf("database error: %v", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return users, nil
+}
+
+// SetUserSuspended men-suspend atau membuka suspend user. Saat suspend semua session user langsung dicabut.
+func (s *Service) SetUserSuspended(adminID, userID uint, suspended bool, ip string) error {
+	if adminID == userID {
+		return ErrCannotModerateSelf
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE public.user SET "suspendedAt" = NULL WHERE "userId" = $1`
+	event := AuditAdminUnsuspendUser
+	if suspended {
+		query = `UPDATE public.user SET "suspendedAt" = COALESCE("suspendedAt", now()) WHERE "userId" = $1`
+		event = AuditAdminSuspendUser
+	}
+	tag, err := tx.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	detail := map[string]interface{}{"userId": userID}
+	if suspended {
+		tag, err := tx.Exec(ctx, `UPDATE "userSession" SET "revokedAt" = now() WHERE "revokedAt" IS NULL AND "userId" = $1`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke session: %v", err)
+		}
+		detail["revokedSessions"] = tag.RowsAffected()
+	}
+	if err := recordAudit(ctx, tx, &adminID, event, ip, detail); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// DeleteUser menghapus akun beserta profil, product, session dan file-nya. Purchase dan file yang
+// masih dipakai purchase (bukti bayar, gambar item) tetap disimpan sebagai riwayat.
+func (s *Service) DeleteUser(adminID, userID uint, ip string) error {
+	if adminID == userID {
+		return ErrCannotModerateSelf
+	}
+
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT true FROM public.user WHERE "userId" = $1 FOR UPDATE`, userID).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+
+	// Refresh token ikut terhapus lewat foreign key ke "userSession"
+	for _, query := range []string{
+		`DELETE FROM product WHERE "userId" = $1`,
+		`DELETE FROM "userSession" WHERE "userId" = $1`,
+		`DELETE FROM "passwordReset" WHERE "userId" = $1`,
+		`DELETE FROM "verificationCode" WHERE "userId" = $1`,
+		`DELETE FROM "userProfile" WHERE "userId" = $1`,
+		`DELETE FROM public.user WHERE "userId" = $1`,
+	} {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return fmt.Errorf("failed to delete user: %v", err)
+		}
+	}
+
+	// Dihapus setelah product dan profil supaya file yang hanya dipakai keduanya ikut terhapus
+	rows, err := tx.Query(ctx,
+		`DELETE FROM file f WHERE f."userId" = $1
+             AND NOT EXISTS(SELECT 1 FROM "userProfile" WHERE "fileId" = f."fileId")
+             AND NOT EXISTS(SELECT 1 FROM product WHERE "fileId" = f."fileId")
+             AND NOT EXISTS(SELECT 1 FROM "purchaseItem" WHERE "fileId" = f."fileId")
+             AND NOT EXISTS(SELECT 1 FROM "purchasePayment" WHERE "fileId" = f."fileId")
+         RETURNING COALESCE(f."objectKeys", '{}')`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete files: %v", err)
+	}
+	var objectKeys []string
+	deletedFiles := 0
+	for rows.Next() {
+		var keys []string
+		if err := rows.Scan(&keys); err != nil {
+			rows.Close()
+			return fmt.Errorf("database error: %v", err)
+		}
+		objectKeys = append(objectKeys, keys...)
+		deletedFiles++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to delete files: %v", err)
+	}
+
+	err = recordAudit(ctx, tx, &adminID, AuditAdminDeleteUser, ip, map[string]interface{}{
+		"userId":       userID,
+		"deletedFiles": deletedFiles,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	// Object dihapus setelah commit; kalau gagal, orphan sweeper yang akan membersihkannya
+	s.storage.DeleteObjects(objectKeys)
+	return nil
+}
+
+// TakeDownProduct menghapus product milik siapa pun
+func (s *Service) TakeDownProduct(adminID, productID uint, ip string) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID uint
+	err = tx.QueryRow(ctx, `DELETE FROM product WHERE "productId" = $1 RETURNING "userId"`, productID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to delete product: %v", err)
+	}
+	err = recordAudit(ctx, tx, &adminID, AuditAdminTakeDownProduct, ip, map[string]interface{}{
+		"productId": productID,
+		"ownerId":   ownerID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// TakeDownFile menghapus file milik siapa pun beserta object-nya. Profil yang memakai file dikosongkan
+// dan product yang memakai file ikut dihapus; purchase tetap menyimpan "fileId" sebagai riwayat.
+func (s *Service) TakeDownFile(adminID, fileID uint, ip string) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID uint
+	var objectKeys []string
+	err = tx.QueryRow(ctx, `SELECT "userId", COALESCE("objectKeys", '{}') FROM file WHERE "fileId" = $1 FOR UPDATE`, fileID).
+		Scan(&ownerID, &objectKeys)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrFileNotFound
+	} else if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE "userProfile" SET "fileId" = NULL WHERE "fileId" = $1`, fileID); err != nil {
+		return fmt.Errorf("failed to update profile: %v", err)
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM product WHERE "fileId" = $1`, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM file WHERE "fileId" = $1`, fileID); err != nil {
+		return fmt.Errorf("failed to delete file: %v", err)
+	}
+	err = recordAudit(ctx, tx, &adminID, AuditAdminTakeDownFile, ip, map[string]interface{}{
+		"fileId":          fileID,
+		"ownerId":         ownerID,
+		"deletedProducts": tag.RowsAffected(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	// Object dihapus setelah commit; kalau gagal, orphan sweeper yang akan membersihkannya
+	s.storage.DeleteObjects(objectKeys)
+	return nil
+}
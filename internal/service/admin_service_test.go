@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
+	"testing"
+)
+
+func countRows(t *testing.T, db *pgxpool.Pool, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(context.Background(), query, args...).Scan(&n); err != nil {
+		t.Fatalf("count rows: %v", err)
+	}
+	return n
+}
+
+func TestSuspendUserRevokesSessions(t *testing.T) {
+	svc, _ := newDBService(t)
+	adminID := createSeller(t, svc, "admin@example.com")
+	userID := createSeller(t, svc, "user@example.com")
+	sessions := make([]*model.Session, 2)
+	for i := range sessions {
+		session, err := svc.CreateSession(userID)
+		if err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+		sessions[i] = session
+	}
+
+	if err := svc.SetUserSuspended(adminID, adminID, true, "127.0.0.1"); !errors.Is(err, service.ErrCannotModerateSelf) {
+		t.Fatalf("suspend self error = %v, want ErrCannotModerateSelf", err)
+	}
+	if err := svc.SetUserSuspended(adminID, 999999, true, "127.0.0.1"); !errors.Is(err, service.ErrUserNotFound) {
+		t.Fatalf("suspend missing user error = %v, want ErrUserNotFound", err)
+	}
+	if err := svc.SetUserSuspended(adminID, userID, true, "127.0.0.1"); err != nil {
+		t.Fatalf("SetUserSuspended: %v", err)
+	}
+
+	for _, session := range sessions {
+		if active, err := svc.IsTokenActive(session.ID, ""); err != nil || active {
+			t.Fatalf("IsTokenActive after suspend = %v, %v, want false", active, err)
+		}
+		if _, _, err := svc.RotateRefreshToken(session.RefreshToken); err == nil {
+			t.Fatal("refresh token still works after suspend")
+		}
+	}
+	if _, err := svc.AuthenticateEmail("user@example.com", "password123", "127.0.0.1"); !errors.Is(err, service.ErrUserSuspended) {
+		t.Fatalf("login while suspended error = %v, want ErrUserSuspended", err)
+	}
+
+	// Membuka suspend mengizinkan login lagi, session lama tetap tercabut
+	if err := svc.SetUserSuspended(adminID, userID, false, "127.0.0.1"); err != nil {
+		t.Fatalf("SetUserSuspended: %v", err)
+	}
+	if _, err := svc.AuthenticateEmail("user@example.com", "password123", "127.0.0.1"); err != nil {
+		t.Fatalf("login after unsuspend: %v", err)
+	}
+	if active, err := svc.IsTokenActive(sessions[0].ID, ""); err != nil || active {
+		t.Fatalf("IsTokenActive after unsuspend = %v, %v, want false", active, err)
+	}
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	svc, db := newDBService(t)
+	adminID := createSeller(t, svc, "admin@example.com")
+	sellerID := createSeller(t, svc, "seller@example.com")
+	buyerID := createSeller(t, svc, "buyer@example.com")
+
+	avatar := addFile(t, svc, sellerID, model.FileVisibilityPublic)
+	if _, err := svc.UpdateUserProfile(sellerID, service.UserProfileUpdate{FileId: &avatar}); err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+	unused := addFile(t, svc, sellerID, model.FileVisibilityPrivate)
+	sold := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+	unsold := createProduct(t, svc, sellerID, "SKU-2", 5, 1000)
+	purchase, err := checkout(svc, buyerID, sold.ProductId, 1)
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	if _, err := svc.CreateSession(sellerID); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	if err := svc.DeleteUser(adminID, adminID, "127.0.0.1"); !errors.Is(err, service.ErrCannotModerateSelf) {
+		t.Fatalf("delete self error = %v, want ErrCannotModerateSelf", err)
+	}
+	if err := svc.DeleteUser(adminID, sellerID, "127.0.0.1"); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	if _, err := svc.GetUserProfile(sellerID); !errors.Is(err, service.ErrUserNotFound) {
+		t.Fatalf("GetUserProfile after delete error = %v, want ErrUserNotFound", err)
+	}
+	for table, query := range map[string]string{
+		"product":     `SELECT count(*) FROM product WHERE "userId" = $1`,
+		"userSession": `SELECT count(*) FROM "userSession" WHERE "userId" = $1`,
+		"userProfile": `SELECT count(*) FROM "userProfile" WHERE "userId" = $1`,
+	} {
+		if n := countRows(t, db, query, sellerID); n != 0 {
+			t.Fatalf("%d %s row(s) left after delete", n, table)
+		}
+	}
+
+	// Gambar product yang sudah dibeli tetap disimpan sebagai riwayat purchase
+	fileCount := `SELECT count(*) FROM file WHERE "fileId" = $1`
+	for _, id := range []uint{avatar, unused, unsold.FileId} {
+		if n := countRows(t, db, fileCount, id); n != 0 {
+			t.Fatalf("file %d still exists after delete", id)
+		}
+	}
+	if n := countRows(t, db, fileCount, sold.FileId); n != 1 {
+		t.Fatalf("file %d used by a purchase was deleted", sold.FileId)
+	}
+	if status := purchaseStatus(t, db, purchase.PurchaseId); status != model.PurchaseStatusPending {
+		t.Fatalf("purchase status after seller delete = %s, want pending", status)
+	}
+
+	if err := svc.DeleteUser(adminID, sellerID, "127.0.0.1"); !errors.Is(err, service.ErrUserNotFound) {
+		t.Fatalf("second delete error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestTakeDownFile(t *testing.T) {
+	svc, db := newDBService(t)
+	adminID := createSeller(t, svc, "admin@example.com")
+	sellerID := createSeller(t, svc, "seller@example.com")
+
+	fileID := addFile(t, svc, sellerID, model.FileVisibilityPublic)
+	if _, err := svc.UpdateUserProfile(sellerID, service.UserProfileUpdate{FileId: &fileID}); err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+	product, err := svc.CreateProduct(&model.Product{
+		Name: "Product", Category: "Food", Qty: 1, Price: 100, Sku: "SKU-1", FileId: fileID, UserId: sellerID,
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	other := createProduct(t, svc, sellerID, "SKU-2", 1, 100)
+
+	if err := svc.TakeDownFile(adminID, fileID, "127.0.0.1"); err != nil {
+		t.Fatalf("TakeDownFile: %v", err)
+	}
+
+	profile, err := svc.GetUserProfile(sellerID)
+	if err != nil {
+		t.Fatalf("GetUserProfile: %v", err)
+	}
+	if profile.FileId != nil || profile.FileUri != nil {
+		t.Fatalf("profile after take down = fileId %v, fileUri %v, want both nil", profile.FileId, profile.FileUri)
+	}
+	if n := countRows(t, db, `SELECT count(*) FROM product WHERE "productId" = $1`, product.ProductId); n != 0 {
+		t.Fatal("product using the taken down file still exists")
+	}
+	if n := countRows(t, db, `SELECT count(*) FROM product WHERE "productId" = $1`, other.ProductId); n != 1 {
+		t.Fatal("product using another file was deleted")
+	}
+	if _, err := svc.GetFile(fileID, sellerID); !errors.Is(err, service.ErrFileNotFound) {
+		t.Fatalf("GetFile after take down error = %v, want ErrFileNotFound", err)
+	}
+
+	if err := svc.TakeDownFile(adminID, fileID, "127.0.0.1"); !errors.Is(err, service.ErrFileNotFound) {
+		t.Fatalf("second take down error = %v, want ErrFileNotFound", err)
+	}
+}
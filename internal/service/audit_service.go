@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"time"
+)
+
+const (
+	AuditLoginLockout   = "login_lockout"
+	AuditIPLoginLockout = "ip_login_lockout"
+
+	AuditAdminSuspendUser     = "admin_suspend_user"
+	AuditAdminUnsuspendUser   = "admin_unsuspend_user"
+	AuditAdminDeleteUser      = "admin_delete_user"
+	AuditAdminTakeDownProduct = "admin_take_down_product"
+	AuditAdminTakeDownFile    = "admin_take_down_file"
+)
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// recordAudit mencatat aksi admin ke tabel "auditLog" di dalam transaksi aksinya.
+// Kejadian di luar transaksi (misal lockout login) dicatat lewat repository.AuditRepository.
+func recordAudit(ctx context.Context, q execer, userID *uint, event, ip string, detail map[string]interface{}) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO "auditLog" ("userId", event, ip, detail, "createdAt") VALUES ($1, $2, $3, $4, $5)`,
+		userID, event, ip, detail, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record audit log: %v", err)
+	}
+	return nil
+}
@@ -2,20 +2,121 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
 	"log"
 	"sprint3/internal/model"
-	"sprint3/pkg/database"
+	"sprint3/internal/repository"
+	"time"
 )
 
-func AddFile(fileURL, thumbnailURL string) (*model.File, error) {
-	db := database.GetDBPool()
-	var file model.File
-	err := db.QueryRow(context.Background(), `INSERT INTO file ("fileUri", "fileThumbnailUri") VALUES ($1, $2) RETURNING "fileId", "fileUri", "fileThumbnailUri"`, fileURL, thumbnailURL).Scan(&file.ID, &file.URI, &file.ThumbnailURI)
-	if err != nil {
+var (
+	ErrFileInUse     = errors.New("file is still referenced")
+	ErrFileNotPublic = errors.New("file is private")
+)
+
+// AddFile mencatat file yang sudah diunggah oleh file.UserId. file.ObjectKeys dipakai orphan sweeper
+// untuk membedakan object yang masih dipakai, dan untuk menghapus object saat file dihapus.
+func (s *Service) AddFile(file *model.File) (*model.File, error) {
+	ctx := context.Background()
+	if file.Visibility == "" {
+		file.Visibility = model.FileVisibilityPublic
+	}
+	file.CreatedAt = time.Now()
+	if err := s.repos.Files.Create(ctx, file); err != nil {
 		log.Printf("Error inserting file into database: %v", err)
 		return nil, err
 	}
 
 	log.Printf("File stored in database: ID = %d, URI = %s, ThumbnailURI = %s", file.ID, file.URI, file.ThumbnailURI)
-	return &file, nil
+	if err := s.signFileURLs(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetFiles mengembalikan file milik userID, terbaru lebih dulu
+func (s *Service) GetFiles(userID uint, limit, offset int) ([]model.File, error) {
+	ctx := context.Background()
+	files, err := s.repos.Files.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range files {
+		if err := s.signFileURLs(ctx, &files[i]); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// GetFile mengembalikan file milik userID, file milik user lain dianggap tidak ada
+func (s *Service) GetFile(fileID, userID uint) (*model.File, error) {
+	ctx := context.Background()
+	file, err := s.getOwnedFile(ctx, fileID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.signFileURLs(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// DeleteFile menghapus file milik userID beserta object-nya di storage.
+// File yang masih dipakai profil, product atau purchase tidak boleh dihapus.
+func (s *Service) DeleteFile(fileID, userID uint) error {
+	file, err := s.repos.Files.Delete(context.Background(), fileID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrFileNotFound
+	} else if errors.Is(err, repository.ErrInUse) {
+		return ErrFileInUse
+	} else if err != nil {
+		return err
+	}
+
+	// Object dihapus setelah commit; kalau gagal, orphan sweeper yang akan membersihkannya
+	s.storage.DeleteObjects(file.ObjectKeys)
+	return nil
+}
+
+// getOwnedFile metadata file milik userID tanpa signed URL
+func (s *Service) getOwnedFile(ctx context.Context, fileID, userID uint) (*model.File, error) {
+	file, err := s.repos.Files.FindByID(ctx, fileID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrFileNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if file.UserId != userID {
+		return nil, ErrFileNotFound
+	}
+	return file, nil
+}
+
+// ownedFileVisibility visibility file fileID milik userID, ErrFileNotFound kalau tidak ada atau milik user lain
+func ownedFileVisibility(ctx context.Context, q rowQuerier, fileID, userID uint) (string, error) {
+	var visibility string
+	err := q.QueryRow(ctx, `SELECT visibility FROM file WHERE "fileId" = $1 AND "userId" = $2`, fileID, userID).
+		Scan(&visibility)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrFileNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("database error: %v", err)
+	}
+	return visibility, nil
+}
+
+// signFileURLs mengganti URL file private dengan signed URL, file public tidak diubah
+func (s *Service) signFileURLs(ctx context.Context, file *model.File) error {
+	if file.Visibility != model.FileVisibilityPrivate {
+		return nil
+	}
+	uris := []*string{&file.URI, &file.ThumbnailURI}
+	for i := range file.Renditions {
+		uris = append(uris, &file.Renditions[i].URI)
+	}
+	return s.storage.SignPrivateURLs(ctx, file.ObjectKeys, uris...)
 }
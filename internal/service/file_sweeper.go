@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log"
+	"sprint3/internal/storage"
+	"time"
+)
+
+// sweepLockID key pg_try_advisory_lock, sama untuk semua instance supaya hanya satu yang menjalankan sweep.
+// Harus berbeda dari lock migrasi di pkg/database.
+const sweepLockID int64 = 7_302_118_541
+
+// sweepBatchSize jumlah key yang dicek ke database dalam satu query
+const sweepBatchSize = 500
+
+// sweptPrefixes prefix key milik aplikasi, object lain di bucket tidak pernah disentuh sweeper
+var sweptPrefixes = []string{storage.PublicPrefix, storage.PrivatePrefix, storage.IncomingPrefix}
+
+// ErrSweepInProgress sweep sedang dijalankan instance lain
+var ErrSweepInProgress = errors.New("orphan sweep already running on another instance")
+
+// SweepOrphanFiles menghapus object di bawah prefix milik aplikasi yang tidak tercatat di "objectKeys" tabel file.
+// Object yang lebih muda dari grace dilewati karena mungkin upload-nya masih berjalan.
+func (s *Service) SweepOrphanFiles(ctx context.Context, grace time.Duration) (int, error) {
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, sweepLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to acquire sweep lock: %v", err)
+	}
+	if !locked {
+		return 0, ErrSweepInProgress
+	}
+	defer func() {
+		// Lock advisory milik session, jadi tetap dilepas walaupun ctx sudah dibatalkan
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, sweepLockID); err != nil {
+			log.Printf("Failed to release sweep lock: %v", err)
+		}
+	}()
+
+	cutoff := time.Now().Add(-grace)
+	deleted := 0
+	var batch []string
+	flush := func() error {
+		n, err := s.deleteUnreferenced(ctx, conn, batch)
+		deleted += n
+		batch = batch[:0]
+		return err
+	}
+
+	for _, prefix := range sweptPrefixes {
+		err := s.storage.List(ctx, prefix, func(object storage.ObjectInfo) error {
+			if object.LastModified.After(cutoff) {
+				return nil
+			}
+			batch = append(batch, object.Key)
+			if len(batch) < sweepBatchSize {
+				return nil
+			}
+			return flush()
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteUnreferenced menghapus key di keys yang tidak ada di "objectKeys" file mana pun
+func (s *Service) deleteUnreferenced(ctx context.Context, conn *pgxpool.Conn, keys []string) (int, error) {
+	rows, err := conn.Query(ctx,
+		`SELECT DISTINCT k FROM file, unnest("objectKeys") k
+         WHERE "objectKeys" && $1 AND k = ANY($1)`,
+		keys)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %v", err)
+	}
+	referenced := map[string]bool{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("database error: %v", err)
+		}
+		referenced[key] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("database error: %v", err)
+	}
+
+	deleted := 0
+	for _, key := range keys {
+		if referenced[key] {
+			continue
+		}
+		// Sweep yang dihentikan di tengah jalan aman, sisanya dihapus di sweep berikutnya
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete orphan object %s: %v", key, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// RunOrphanSweeper menjalankan SweepOrphanFiles setiap interval sampai ctx dibatalkan.
+// Blocking, pemanggil menjalankannya di goroutine sendiri supaya bisa menunggunya selesai saat shutdown.
+func (s *Service) RunOrphanSweeper(ctx context.Context, interval, grace time.Duration) {
+	if interval <= 0 {
+		log.Println("Orphan sweeper disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.SweepOrphanFiles(ctx, grace)
+			if errors.Is(err, ErrSweepInProgress) {
+				log.Println("Orphan sweep skipped, another instance is sweeping")
+				continue
+			} else if err != nil {
+				log.Printf("Orphan sweep failed: %v", err)
+				continue
+			}
+			log.Printf("Orphan sweep finished, %d object(s) deleted", deleted)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DependencyStatus hasil ping satu dependensi, Err nil berarti dependensi bisa dipakai
+type DependencyStatus struct {
+	Name    string
+	Latency time.Duration
+	Err     error
+}
+
+// CheckDependencies ping database dan storage bersamaan, masing-masing dibatasi timeout
+func (s *Service) CheckDependencies(ctx context.Context, timeout time.Duration) []DependencyStatus {
+	type check struct {
+		name string
+		ping func(ctx context.Context) error
+	}
+	checks := []check{{"storage", s.storage.Ping}}
+	// Service yang hanya memakai repository memori (misal di test) tidak punya database
+	if s.db != nil {
+		checks = append([]check{{"database", s.db.Ping}}, checks...)
+	}
+
+	results := make([]DependencyStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, name string, ping func(ctx context.Context) error) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := ping(ctx)
+			results[i] = DependencyStatus{Name: name, Latency: time.Since(start), Err: err}
+		}(i, check.name, check.ping)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sprint3/internal/repository"
+	"sprint3/pkg/config"
+	"time"
+)
+
+var ErrLoginLocked = errors.New("login temporarily locked")
+
+// LoginLockedError login ditolak sementara karena terlalu banyak percobaan gagal
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
+
+// loginGuard kunci percobaan login untuk satu akun dan satu IP
+type loginGuard struct {
+	attempts   repository.LoginAttemptRepository
+	audit      repository.AuditRepository
+	policy     *config.Config
+	accountKey string
+	ipKey      string
+	ip         string
+}
+
+func (s *Service) newLoginGuard(identifierType, identifier, ip string) loginGuard {
+	return loginGuard{
+		attempts:   s.repos.LoginAttempts,
+		audit:      s.repos.Audit,
+		policy:     s.cfg,
+		accountKey: "account:" + identifierType + ":" + identifier,
+		ipKey:      "ip:" + ip,
+		ip:         ip,
+	}
+}
+
+// check menolak login kalau akun atau IP sedang terkunci
+func (g loginGuard) check(ctx context.Context) error {
+	lockedUntil, err := g.attempts.LockedUntil(ctx, []string{g.accountKey, g.ipKey})
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil {
+		return &LoginLockedError{RetryAfter: time.Until(*lockedUntil)}
+	}
+	return nil
+}
+
+// recordFailure menambah hitungan gagal akun dan IP, lalu mengunci yang melewati batas.
+// userID nil kalau akun tidak ditemukan.
+func (g loginGuard) recordFailure(ctx context.Context, userID *uint) error {
+	if err := g.recordKeyFailure(ctx, g.accountKey, g.policy.LoginMaxFailures, userID, AuditLoginLockout); err != nil {
+		return err
+	}
+	return g.recordKeyFailure(ctx, g.ipKey, g.policy.LoginIPMaxFailures, nil, AuditIPLoginLockout)
+}
+
+func (g loginGuard) recordKeyFailure(ctx context.Context, key string, maxFailures int64, userID *uint, event string) error {
+	// Hitungan dimulai lagi dari 1 kalau gagal terakhir (atau akhir lockout) sudah di luar window,
+	// jadi gagal lagi tepat setelah lockout selesai tetap memperpanjang lockout berikutnya
+	failures, err := g.attempts.RecordFailure(ctx, key, g.policy.LoginFailureWindow)
+	if err != nil {
+		return err
+	}
+	if failures < maxFailures {
+		return nil
+	}
+
+	lockout := g.lockoutDuration(failures - maxFailures)
+	if err := g.attempts.Lock(ctx, key, time.Now().Add(lockout)); err != nil {
+		return err
+	}
+
+	log.Printf("Login locked for %s after %d failures (%s)", key, failures, lockout)
+	return g.audit.Record(ctx, repository.AuditEntry{
+		UserId: userID,
+		Event:  event,
+		IP:     g.ip,
+		Detail: map[string]interface{}{
+			"key":      key,
+			"failures": failures,
+			"lockout":  lockout.String(),
+		},
+	})
+}
+
+// recordSuccess menghapus hitungan gagal akun, hitungan IP tetap supaya tidak bisa direset dengan akun sendiri
+func (g loginGuard) recordSuccess(ctx context.Context) error {
+	return g.attempts.Reset(ctx, g.accountKey)
+}
+
+// lockoutDuration LoginLockoutBase * 2^excess, maksimal LoginLockoutMax
+func (g loginGuard) lockoutDuration(excess int64) time.Duration {
+	lockout := g.policy.LoginLockoutBase
+	for i := int64(0); i < excess && lockout < g.policy.LoginLockoutMax; i++ {
+		lockout *= 2
+	}
+	if lockout > g.policy.LoginLockoutMax {
+		lockout = g.policy.LoginLockoutMax
+	}
+	return lockout
+}
+
+// PruneLoginAttempts menghapus hitungan login gagal yang sudah di luar LoginFailureWindow.
+// Hitungan seperti itu dimulai lagi dari 1 saat gagal berikutnya, jadi menghapusnya tidak mengubah lockout.
+func (s *Service) PruneLoginAttempts(ctx context.Context) (int64, error) {
+	return s.repos.LoginAttempts.Prune(ctx, s.cfg.LoginFailureWindow)
+}
+
+// RunLoginAttemptPruner menjalankan PruneLoginAttempts setiap interval sampai ctx dibatalkan.
+// Blocking, sama seperti RunPurchaseExpirer.
+func (s *Service) RunLoginAttemptPruner(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Login attempt pruner disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.PruneLoginAttempts(ctx)
+			if err != nil {
+				log.Printf("Pruning login attempts failed: %v", err)
+				continue
+			}
+			if pruned > 0 {
+				log.Printf("Pruned %d stale login attempt(s)", pruned)
+			}
+		}
+	}
+}
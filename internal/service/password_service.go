@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"time"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// RequestPasswordReset mengirim token reset ke email/nomor telepon akun di background, jadi akun yang ada
+// maupun tidak dibalas sama cepat dan kegagalan notifier tidak terlihat oleh pemanggil.
+// Dengan PasswordResetRequireVerified token hanya dikirim ke kontak yang sudah diverifikasi.
+func (s *Service) RequestPasswordReset(channel, identifier string) {
+	s.goBackground("password reset", func(ctx context.Context) error {
+		return s.sendPasswordReset(ctx, channel, identifier)
+	})
+}
+
+// sendPasswordReset akun yang tidak ada, kontak yang belum diverifikasi dan permintaan ulang
+// sebelum PasswordResetResendInterval dilewati tanpa error
+func (s *Service) sendPasswordReset(ctx context.Context, channel, identifier string) error {
+	contact := repository.ContactEmail
+	if channel == notifier.ChannelSMS {
+		contact = repository.ContactPhone
+	}
+
+	user, err := s.repos.Users.FindByContact(ctx, contact, identifier)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Password reset requested for unknown %s", contact)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Kontak yang belum diverifikasi bisa saja bukan milik pemilik akun
+	if s.cfg.PasswordResetRequireVerified {
+		profile, err := s.repos.Profiles.Get(ctx, user.Id)
+		if err != nil {
+			return err
+		}
+		verified := profile.EmailVerified
+		if contact == repository.ContactPhone {
+			verified = profile.PhoneVerified
+		}
+		if !verified {
+			log.Printf("Password reset requested for unverified %s of user %d", contact, user.Id)
+			return nil
+		}
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.PasswordResetTTL)
+
+	// Hanya token terakhir yang berlaku
+	err = s.repos.PasswordResets.Replace(ctx, user.Id, hashToken(token), expiresAt, now.Add(-s.cfg.PasswordResetResendInterval))
+	if errors.Is(err, repository.ErrTooSoon) {
+		log.Printf("Password reset for user %d requested again too soon", user.Id)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, notifier.Message{
+		Channel: channel,
+		To:      identifier,
+		Subject: "Reset password",
+		Body: fmt.Sprintf("Token reset password Anda: %s\nBerlaku sampai %s. Abaikan pesan ini kalau Anda tidak memintanya.",
+			token, expiresAt.Format(time.RFC1123)),
+	})
+}
+
+// ResetPassword mengganti password dengan token dari RequestPasswordReset.
+// Token hanya bisa dipakai sekali dan semua session user dicabut.
+func (s *Service) ResetPassword(token, newPassword string) error {
+	ctx := context.Background()
+
+	// Token langsung dihapus supaya tidak bisa dipakai dua kali
+	userID, expiresAt, err := s.repos.PasswordResets.Consume(ctx, hashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+	if time.Now().After(expiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	return s.updatePassword(ctx, userID, newPassword, "")
+}
+
+// ChangePassword mengganti password user yang login setelah password lama dicek.
+// Session lain dicabut, session yang sedang dipakai tetap berlaku.
+func (s *Service) ChangePassword(userID uint, sessionID, oldPassword, newPassword string) error {
+	ctx := context.Background()
+
+	user, err := s.repos.Users.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUserNotFound
+	} else if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	return s.updatePassword(ctx, userID, newPassword, sessionID)
+}
+
+// updatePassword menyimpan hash password baru, semua session kecuali keepSessionID ikut dicabut
+func (s *Service) updatePassword(ctx context.Context, userID uint, password, keepSessionID string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %v", err)
+	}
+	err = s.repos.Users.UpdatePassword(ctx, userID, string(hashedPassword), keepSessionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/service"
+	"sprint3/internal/storage"
+	"sprint3/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestPasswordReset(t *testing.T) {
+	svc, notify := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	session, _ := svc.CreateSession(user.Id)
+	verifyContact(t, svc, notify, user.Id, notifier.ChannelEmail)
+	sent := notify.count()
+
+	// Akun yang tidak ada tidak menghasilkan error maupun pesan
+	svc.RequestPasswordReset(notifier.ChannelEmail, "nobody@example.com")
+	svc.Wait()
+	if notify.count() != sent {
+		t.Fatal("message sent for an unknown account")
+	}
+
+	svc.RequestPasswordReset(notifier.ChannelEmail, "buyer@example.com")
+	svc.Wait()
+	token := notify.secret(t, "Token reset password Anda: ")
+
+	// Permintaan ulang sebelum PasswordResetResendInterval diabaikan dan token pertama tetap berlaku
+	svc.RequestPasswordReset(notifier.ChannelEmail, "buyer@example.com")
+	svc.Wait()
+	if notify.count() != sent+1 {
+		t.Fatalf("%d reset message(s) sent, want 1", notify.count()-sent)
+	}
+
+	if err := svc.ResetPassword(token, "newpassword123"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if err := svc.ResetPassword(token, "another123"); !errors.Is(err, service.ErrInvalidResetToken) {
+		t.Fatalf("reused reset token error = %v, want ErrInvalidResetToken", err)
+	}
+
+	if _, err := svc.AuthenticateEmail("buyer@example.com", "newpassword123", "10.0.0.1"); err != nil {
+		t.Fatalf("login with new password: %v", err)
+	}
+	if active, _ := svc.IsTokenActive(session.ID, "jti"); active {
+		t.Fatal("session still active after password reset")
+	}
+}
+
+func TestPasswordResetRequiresVerifiedContact(t *testing.T) {
+	svc, notify := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	if _, err := svc.LinkPhone(user.Id, "+628111"); err != nil {
+		t.Fatalf("LinkPhone: %v", err)
+	}
+	verifyContact(t, svc, notify, user.Id, notifier.ChannelEmail)
+	sent := notify.count()
+
+	// Nomor telepon belum diverifikasi, jadi tidak ada pesan walaupun akunnya ada
+	svc.RequestPasswordReset(notifier.ChannelSMS, "+628111")
+	svc.Wait()
+	if notify.count() != sent {
+		t.Fatal("reset token sent to an unverified phone")
+	}
+
+	svc.RequestPasswordReset(notifier.ChannelEmail, "buyer@example.com")
+	svc.Wait()
+	if notify.count() != sent+1 {
+		t.Fatal("no reset token sent to the verified email")
+	}
+}
+
+func TestPasswordResetToUnverifiedContactWhenAllowed(t *testing.T) {
+	cfg := testutil.Config()
+	cfg.PasswordResetRequireVerified = false
+	notify := &recordingNotifier{}
+	svc := service.New(cfg, nil, repository.NewMemory(), &storage.Client{}, notify)
+	if _, err := svc.RegisterUserEmail("buyer@example.com", "password123"); err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+
+	svc.RequestPasswordReset(notifier.ChannelEmail, "buyer@example.com")
+	svc.Wait()
+	if notify.count() != 1 {
+		t.Fatalf("%d reset message(s) sent to the unverified email, want 1", notify.count())
+	}
+}
+
+// blockingNotifier menahan Send sampai release ditutup lalu mengembalikan error
+type blockingNotifier struct {
+	release chan struct{}
+}
+
+func (n *blockingNotifier) Send(ctx context.Context, msg notifier.Message) error {
+	<-n.release
+	return errors.New("notifier unavailable")
+}
+
+func TestPasswordResetDoesNotWaitForNotifier(t *testing.T) {
+	cfg := testutil.Config()
+	cfg.PasswordResetRequireVerified = false
+	notify := &blockingNotifier{release: make(chan struct{})}
+	svc := service.New(cfg, nil, repository.NewMemory(), &storage.Client{}, notify)
+	if _, err := svc.RegisterUserEmail("buyer@example.com", "password123"); err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+
+	// Akun yang ada dibalas sama cepat dengan akun yang tidak ada, kegagalan notifier hanya di-log
+	returned := make(chan struct{})
+	go func() {
+		svc.RequestPasswordReset(notifier.ChannelEmail, "buyer@example.com")
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("RequestPasswordReset waited for the notifier")
+	}
+	close(notify.release)
+	svc.Wait()
+}
+
+func TestChangePasswordKeepsCurrentSession(t *testing.T) {
+	svc, _ := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	current, _ := svc.CreateSession(user.Id)
+	other, _ := svc.CreateSession(user.Id)
+
+	if err := svc.ChangePassword(user.Id, current.ID, "wrong", "newpassword123"); !errors.Is(err, service.ErrInvalidPassword) {
+		t.Fatalf("wrong old password error = %v, want ErrInvalidPassword", err)
+	}
+	if err := svc.ChangePassword(user.Id, current.ID, "password123", "newpassword123"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	if active, _ := svc.IsTokenActive(current.ID, "jti"); !active {
+		t.Fatal("current session revoked by password change")
+	}
+	if active, _ := svc.IsTokenActive(other.ID, "jti"); active {
+		t.Fatal("other session still active after password change")
+	}
+}
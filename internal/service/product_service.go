@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"sprint3/internal/model"
+	"strings"
+	"time"
+)
+
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrNotProductOwner  = errors.New("product belongs to another user")
+	ErrSkuAlreadyExists = errors.New("sku already exists")
+)
+
+const (
+	ProductSortNewest    = "newest"
+	ProductSortOldest    = "oldest"
+	ProductSortCheapest  = "cheapest"
+	ProductSortExpensive = "expensive"
+	ProductSortMostSold  = "sold"
+)
+
+var productSortClauses = map[string]string{
+	ProductSortNewest:    `p."createdAt" DESC`,
+	ProductSortOldest:    `p."createdAt" ASC`,
+	ProductSortCheapest:  `p.price ASC`,
+	ProductSortExpensive: `p.price DESC`,
+	ProductSortMostSold:  `p.sold DESC`,
+}
+
+// ProductFilter berisi filter untuk GET /v1/product, field kosong berarti tidak difilter
+type ProductFilter struct {
+	Limit     int
+	Offset    int
+	ProductId *uint
+	Sku       string
+	Category  string
+	SortBy    string
+}
+
+const selectProductQuery = `SELECT p."productId", p.name, p.category, p.qty, p.price, p.sku, p."fileId",
+	COALESCE(f."fileUri", ''), COALESCE(f."fileThumbnailUri", ''), p."userId", p.sold, p."createdAt", p."updatedAt"
+	FROM product p
+	LEFT JOIN file f ON f."fileId" = p."fileId"`
+
+func (s *Service) CreateProduct(product *model.Product) (*model.Product, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := validateProductReferences(ctx, tx, product, 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var productID uint
+	err = tx.QueryRow(ctx,
+		`INSERT INTO product (name, category, qty, price, sku, "fileId", "userId", "createdAt", "updatedAt")
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+         RETURNING "productId"`,
+		product.Name, product.Category, product.Qty, product.Price, product.Sku, product.FileId, product.UserId, now,
+	).Scan(&productID)
+	if isUniqueViolation(err) {
+		// Create bersamaan dengan sku yang sama lolos dari cek validateProductReferences
+		return nil, ErrSkuAlreadyExists
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to create product: %v", err)
+	}
+
+	created, err := getProduct(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return created, nil
+}
+
+// UpdateProduct mengganti seluruh field product milik product.UserId
+func (s *Service) UpdateProduct(product *model.Product) (*model.Product, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkProductOwner(ctx, tx, product.ProductId, product.UserId); err != nil {
+		return nil, err
+	}
+	if err := validateProductReferences(ctx, tx, product, product.ProductId); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE product SET name = $1, category = $2, qty = $3, price = $4, sku = $5, "fileId" = $6, "updatedAt" = $7
+         WHERE "productId" = $8`,
+		product.Name, product.Category, product.Qty, product.Price, product.Sku, product.FileId, time.Now(), product.ProductId,
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrSkuAlreadyExists
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to update product: %v", err)
+	}
+
+	updated, err := getProduct(ctx, tx, product.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return updated, nil
+}
+
+func (s *Service) DeleteProduct(productID, userID uint) error {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkProductOwner(ctx, tx, productID, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product WHERE "productId" = $1`, productID); err != nil {
+		return fmt.Errorf("failed to delete product: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+func (s *Service) GetProducts(filter ProductFilter) ([]model.Product, error) {
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if filter.ProductId != nil {
+		addCondition(`p."productId" = $%d`, *filter.ProductId)
+	}
+	if filter.Sku != "" {
+		addCondition(`p.sku = $%d`, filter.Sku)
+	}
+	if filter.Category != "" {
+		addCondition(`p.category = $%d`, filter.Category)
+	}
+
+	// Sort yang tidak dikenal diabaikan dan memakai default
+	orderBy, ok := productSortClauses[filter.SortBy]
+	if !ok {
+		orderBy = productSortClauses[ProductSortNewest]
+	}
+
+	query := selectProductQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(` ORDER BY %s, p."productId" DESC LIMIT $%d OFFSET $%d`, orderBy, len(args)-1, len(args))
+
+	rows, err := s.db.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer rows.Close()
+
+	products := []model.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %v", err)
+		}
+		products = append(products, *product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return products, nil
+}
+
+func getProduct(ctx context.Context, q rowQuerier, productID uint) (*model.Product, error) {
+	product, err := scanProduct(q.QueryRow(ctx, selectProductQuery+` WHERE p."productId" = $1`, productID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrProductNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return product, nil
+}
+
+func scanProduct(row pgx.Row) (*model.Product, error) {
+	var p model.Product
+	err := row.Scan(&p.ProductId, &p.Name, &p.Category, &p.Qty, &p.Price, &p.Sku, &p.FileId,
+		&p.FileUri, &p.FileThumbnailUri, &p.UserId, &p.Sold, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// checkProductOwner mengunci baris product dan memastikan pemiliknya adalah userID
+func checkProductOwner(ctx context.Context, q rowQuerier, productID, userID uint) error {
+	var ownerID uint
+	err := q.QueryRow(ctx, `SELECT "userId" FROM product WHERE "productId" = $1 FOR UPDATE`, productID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	} else if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if ownerID != userID {
+		return ErrNotProductOwner
+	}
+	return nil
+}
+
+// isUniqueViolation pelanggaran UNIQUE ("userId", sku) yang tidak tertangkap cek sebelum INSERT/UPDATE
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// validateProductReferences memastikan fileId milik seller dan sku belum dipakai product lain milik seller yang sama
+func validateProductReferences(ctx context.Context, q rowQuerier, product *model.Product, excludeProductID uint) error {
+	var visibility *string
+	var skuTaken bool
+	err := q.QueryRow(ctx,
+		`SELECT (SELECT visibility FROM file WHERE "fileId" = $1 AND "userId" = $2),
+                EXISTS(SELECT 1 FROM product WHERE "userId" = $2 AND sku = $3 AND "productId" <> $4)`,
+		product.FileId, product.UserId, product.Sku, excludeProductID,
+	).Scan(&visibility, &skuTaken)
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if visibility == nil {
+		return ErrFileNotFound
+	}
+	// Product bisa dilihat siapa saja, jadi gambarnya harus public
+	if *visibility != model.FileVisibilityPublic {
+		return ErrFileNotPublic
+	}
+	if skuTaken {
+		return ErrSkuAlreadyExists
+	}
+	return nil
+}
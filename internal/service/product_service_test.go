@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
+	"sync"
+	"testing"
+)
+
+func productIDs(products []model.Product) []uint {
+	ids := make([]uint, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ProductId)
+	}
+	return ids
+}
+
+func equalIDs(got, want []uint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCreateUpdateDeleteProduct(t *testing.T) {
+	svc, _ := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+	otherID := createSeller(t, svc, "other@example.com")
+	product := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+	if product.UserId != sellerID || product.Qty != 5 || product.FileUri == "" {
+		t.Fatalf("created product = %+v", product)
+	}
+
+	update := *product
+	update.Name, update.Category, update.Price, update.Qty = "Renamed", "Tools", 2500, 7
+	update.UserId = otherID
+	if _, err := svc.UpdateProduct(&update); !errors.Is(err, service.ErrNotProductOwner) {
+		t.Fatalf("update by another seller error = %v, want ErrNotProductOwner", err)
+	}
+	update.UserId = sellerID
+	missing := update
+	missing.ProductId = product.ProductId + 1000
+	if _, err := svc.UpdateProduct(&missing); !errors.Is(err, service.ErrProductNotFound) {
+		t.Fatalf("update missing product error = %v, want ErrProductNotFound", err)
+	}
+	updated, err := svc.UpdateProduct(&update)
+	if err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if updated.Name != "Renamed" || updated.Category != "Tools" || updated.Price != 2500 || updated.Qty != 7 {
+		t.Fatalf("updated product = %+v", updated)
+	}
+
+	if err := svc.DeleteProduct(product.ProductId, otherID); !errors.Is(err, service.ErrNotProductOwner) {
+		t.Fatalf("delete by another seller error = %v, want ErrNotProductOwner", err)
+	}
+	if err := svc.DeleteProduct(product.ProductId, sellerID); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	if err := svc.DeleteProduct(product.ProductId, sellerID); !errors.Is(err, service.ErrProductNotFound) {
+		t.Fatalf("second delete error = %v, want ErrProductNotFound", err)
+	}
+	if products, err := svc.GetProducts(service.ProductFilter{ProductId: &product.ProductId, Limit: 10}); err != nil || len(products) != 0 {
+		t.Fatalf("GetProducts after delete = %v, %v, want none", products, err)
+	}
+}
+
+func TestProductReferencesAreValidated(t *testing.T) {
+	svc, _ := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+	otherID := createSeller(t, svc, "other@example.com")
+	first := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+	second := createProduct(t, svc, sellerID, "SKU-2", 5, 1000)
+
+	newProduct := func(sku string, fileID uint) *model.Product {
+		return &model.Product{Name: "Product", Category: "Food", Qty: 1, Price: 100, Sku: sku, FileId: fileID, UserId: sellerID}
+	}
+	tests := []struct {
+		name    string
+		product *model.Product
+		wantErr error
+	}{
+		{name: "file of another user", product: newProduct("SKU-3", addFile(t, svc, otherID, model.FileVisibilityPublic)), wantErr: service.ErrFileNotFound},
+		{name: "missing file", product: newProduct("SKU-3", 999999), wantErr: service.ErrFileNotFound},
+		{name: "private file", product: newProduct("SKU-3", addFile(t, svc, sellerID, model.FileVisibilityPrivate)), wantErr: service.ErrFileNotPublic},
+		{name: "duplicate sku", product: newProduct("SKU-1", first.FileId), wantErr: service.ErrSkuAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateProduct(tt.product); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateProduct error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Sku hanya unik per seller, dan product boleh menyimpan sku-nya sendiri saat diubah
+	createProduct(t, svc, otherID, "SKU-1", 5, 1000)
+	keep := *second
+	keep.Price = 1500
+	if _, err := svc.UpdateProduct(&keep); err != nil {
+		t.Fatalf("update keeping own sku: %v", err)
+	}
+	taken := *second
+	taken.Sku = first.Sku
+	if _, err := svc.UpdateProduct(&taken); !errors.Is(err, service.ErrSkuAlreadyExists) {
+		t.Fatalf("update to another product's sku error = %v, want ErrSkuAlreadyExists", err)
+	}
+}
+
+func TestConcurrentCreateProductSameSku(t *testing.T) {
+	svc, _ := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+	fileID := addFile(t, svc, sellerID, model.FileVisibilityPublic)
+
+	// Semua create lolos cek sku, constraint UNIQUE yang menolak sisanya dengan ErrSkuAlreadyExists
+	const creates = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, creates)
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreateProduct(&model.Product{
+				Name: "Product", Category: "Food", Qty: 1, Price: 100, Sku: "SKU-1", FileId: fileID, UserId: sellerID,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+		} else if !errors.Is(err, service.ErrSkuAlreadyExists) {
+			t.Fatalf("CreateProduct error = %v, want ErrSkuAlreadyExists", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d products created, want 1", created)
+	}
+}
+
+func TestGetProductsFiltersAndSort(t *testing.T) {
+	svc, db := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+
+	cheap := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+	pricey := createProduct(t, svc, sellerID, "SKU-2", 5, 9000)
+	middle := createProduct(t, svc, sellerID, "SKU-3", 5, 5000)
+	middle.Category = "Tools"
+	if _, err := svc.UpdateProduct(middle); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	_, err := db.Exec(context.Background(), `UPDATE product SET sold = "productId" * 10 WHERE "productId" = ANY($1)`,
+		[]int64{int64(cheap.ProductId), int64(middle.ProductId)})
+	if err != nil {
+		t.Fatalf("set sold: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter service.ProductFilter
+		want   []uint
+	}{
+		{name: "default newest", filter: service.ProductFilter{}, want: []uint{middle.ProductId, pricey.ProductId, cheap.ProductId}},
+		{name: "unknown sort", filter: service.ProductFilter{SortBy: "random"}, want: []uint{middle.ProductId, pricey.ProductId, cheap.ProductId}},
+		{name: "oldest", filter: service.ProductFilter{SortBy: service.ProductSortOldest}, want: []uint{cheap.ProductId, pricey.ProductId, middle.ProductId}},
+		{name: "cheapest", filter: service.ProductFilter{SortBy: service.ProductSortCheapest}, want: []uint{cheap.ProductId, middle.ProductId, pricey.ProductId}},
+		{name: "expensive", filter: service.ProductFilter{SortBy: service.ProductSortExpensive}, want: []uint{pricey.ProductId, middle.ProductId, cheap.ProductId}},
+		{name: "most sold", filter: service.ProductFilter{SortBy: service.ProductSortMostSold}, want: []uint{middle.ProductId, cheap.ProductId, pricey.ProductId}},
+		{name: "category", filter: service.ProductFilter{Category: "Tools"}, want: []uint{middle.ProductId}},
+		{name: "sku", filter: service.ProductFilter{Sku: "SKU-2"}, want: []uint{pricey.ProductId}},
+		{name: "product id", filter: service.ProductFilter{ProductId: &cheap.ProductId}, want: []uint{cheap.ProductId}},
+		{name: "sku and category mismatch", filter: service.ProductFilter{Sku: "SKU-2", Category: "Tools"}, want: []uint{}},
+		{name: "limit and offset", filter: service.ProductFilter{SortBy: service.ProductSortCheapest, Limit: 1, Offset: 1}, want: []uint{middle.ProductId}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			if filter.Limit == 0 {
+				filter.Limit = 10
+			}
+			products, err := svc.GetProducts(filter)
+			if err != nil {
+				t.Fatalf("GetProducts: %v", err)
+			}
+			if got := productIDs(products); !equalIDs(got, tt.want) {
+				t.Fatalf("product ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,500 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"log"
+	"sort"
+	"sprint3/internal/model"
+	"time"
+)
+
+var (
+	ErrPurchaseNotFound     = errors.New("purchase not found")
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrPurchaseAlreadyPaid  = errors.New("purchase already paid")
+	ErrPaymentProofMismatch = errors.New("number of payment proofs does not match number of sellers")
+	ErrSellerBankMissing    = errors.New("seller has no bank account")
+	ErrPaymentProofPublic   = errors.New("payment proof must be a private file")
+	ErrPurchaseClosed       = errors.New("purchase has been cancelled or expired")
+)
+
+// CreatePurchase membuat order dari keranjang dan menahan stoknya sampai order dibayar,
+// dibatalkan atau melewati PurchaseReservationTTL. "sold" baru bertambah di PayPurchase.
+// Hanya ProductId dan Qty pada purchase.Items yang dibaca, sisanya diisi dari tabel product.
+func (s *Service) CreatePurchase(purchase *model.Purchase) (*model.Purchase, error) {
+	ctx := context.Background()
+
+	items := mergePurchaseItems(purchase.Items)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Stok dikurangi dengan UPDATE bersyarat, jadi dua checkout bersamaan tidak bisa oversell.
+	// Urutan productId yang konsisten mencegah deadlock antar transaksi.
+	totals := map[uint]int{}
+	totalPrice := 0
+	for i := range items {
+		item := &items[i]
+		err := tx.QueryRow(ctx,
+			`WITH reserved AS (
+                 UPDATE product SET qty = qty - $1
+                 WHERE "productId" = $2 AND qty >= $1
+                 RETURNING "userId", name, category, sku, price, "fileId"
+             )
+             SELECT r."userId", r.name, r.category, r.sku, r.price, r."fileId",
+                    COALESCE(f."fileUri", ''), COALESCE(f."fileThumbnailUri", '')
+             FROM reserved r
+             LEFT JOIN file f ON f."fileId" = r."fileId"`,
+			item.Qty, item.ProductId,
+		).Scan(&item.SellerId, &item.Name, &item.Category, &item.Sku, &item.Price, &item.FileId,
+			&item.FileUri, &item.FileThumbnailUri)
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := getProduct(ctx, tx, item.ProductId); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: product %d", ErrInsufficientStock, item.ProductId)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to reserve stock: %v", err)
+		}
+
+		totals[item.SellerId] += item.Price * item.Qty
+		totalPrice += item.Price * item.Qty
+	}
+
+	payments := paymentDetailsFromTotals(totals)
+	if err := fillSellerBankAccounts(ctx, tx, payments); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	created := &model.Purchase{
+		UserId:              purchase.UserId,
+		SenderName:          purchase.SenderName,
+		SenderContactType:   purchase.SenderContactType,
+		SenderContactDetail: purchase.SenderContactDetail,
+		Items:               items,
+		Payments:            payments,
+		TotalPrice:          totalPrice,
+		Status:              model.PurchaseStatusPending,
+		CreatedAt:           now,
+		ExpiresAt:           now.Add(s.cfg.PurchaseReservationTTL),
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO purchase ("userId", "senderName", "senderContactType", "senderContactDetail", "totalPrice", status, "createdAt", "expiresAt")
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+         RETURNING "purchaseId"`,
+		created.UserId, created.SenderName, created.SenderContactType, created.SenderContactDetail,
+		created.TotalPrice, created.Status, created.CreatedAt, created.ExpiresAt,
+	).Scan(&created.PurchaseId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create purchase: %v", err)
+	}
+
+	for _, item := range created.Items {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO "purchaseItem" ("purchaseId", "productId", "sellerId", name, category, sku, price, qty, "fileId")
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			created.PurchaseId, item.ProductId, item.SellerId, item.Name, item.Category, item.Sku, item.Price, item.Qty, item.FileId,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert purchase item: %v", err)
+		}
+	}
+
+	for _, payment := range created.Payments {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO "purchasePayment" ("purchaseId", "sellerId", "bankAccountName", "bankAccountHolder", "bankAccountNumber", "totalPrice")
+             VALUES ($1, $2, $3, $4, $5, $6)`,
+			created.PurchaseId, payment.SellerId, payment.BankAccountName, payment.BankAccountHolder,
+			payment.BankAccountNumber, payment.TotalPrice,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert payment detail: %v", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return created, nil
+}
+
+// PayPurchase melampirkan bukti transfer ke order milik userID.
+// Urutan fileIDs mengikuti urutan paymentDetails yang dikembalikan saat checkout.
+func (s *Service) PayPurchase(purchaseID, userID uint, fileIDs []uint) (*model.Purchase, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	expired, err := lockPendingPurchase(ctx, tx, purchaseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		// Reservasi sudah habis tapi belum dilepas job, stoknya dilepas sekarang
+		if err := releasePurchase(ctx, tx, purchaseID, model.PurchaseStatusExpired); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %v", err)
+		}
+		return nil, ErrPurchaseClosed
+	}
+
+	payments, err := getPaymentDetails(ctx, tx, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) != len(fileIDs) {
+		return nil, ErrPaymentProofMismatch
+	}
+
+	for i, fileID := range fileIDs {
+		// Bukti transfer berisi data rekening, jadi hanya file private yang diterima
+		visibility, err := ownedFileVisibility(ctx, tx, fileID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if visibility != model.FileVisibilityPrivate {
+			return nil, ErrPaymentProofPublic
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE "purchasePayment" SET "fileId" = $1 WHERE "purchaseId" = $2 AND "sellerId" = $3`,
+			fileID, purchaseID, payments[i].SellerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach payment proof: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE purchase SET status = $1, "paidAt" = $2 WHERE "purchaseId" = $3`,
+		model.PurchaseStatusPaid, time.Now(), purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update purchase: %v", err)
+	}
+
+	// Product yang sudah dihapus dilewati, riwayat purchase-nya tetap ada
+	_, err = tx.Exec(ctx,
+		`UPDATE product p SET sold = p.sold + i.qty
+         FROM "purchaseItem" i
+         WHERE i."purchaseId" = $1 AND p."productId" = i."productId"`,
+		purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sold count: %v", err)
+	}
+
+	purchase, err := getPurchase(ctx, tx, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return purchase, nil
+}
+
+// CancelPurchase membatalkan order pending milik userID dan mengembalikan stoknya
+func (s *Service) CancelPurchase(purchaseID, userID uint) (*model.Purchase, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	expired, err := lockPendingPurchase(ctx, tx, purchaseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := model.PurchaseStatusCancelled
+	if expired {
+		status = model.PurchaseStatusExpired
+	}
+	if err := releasePurchase(ctx, tx, purchaseID, status); err != nil {
+		return nil, err
+	}
+
+	purchase, err := getPurchase(ctx, tx, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return purchase, nil
+}
+
+// ReleaseExpiredPurchases menandai order pending yang melewati "expiresAt" sebagai expired dan mengembalikan stoknya.
+// SKIP LOCKED membuat beberapa instance bisa menjalankannya bersamaan tanpa saling menunggu.
+func (s *Service) ReleaseExpiredPurchases(ctx context.Context) (int, error) {
+	released := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return released, err
+		}
+
+		ok, err := s.releaseNextExpiredPurchase(ctx)
+		if err != nil {
+			return released, err
+		}
+		if !ok {
+			return released, nil
+		}
+		released++
+	}
+}
+
+func (s *Service) releaseNextExpiredPurchase(ctx context.Context) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var purchaseID uint
+	err = tx.QueryRow(ctx,
+		`SELECT "purchaseId" FROM purchase
+         WHERE status = $1 AND "expiresAt" <= now()
+         ORDER BY "expiresAt"
+         LIMIT 1
+         FOR UPDATE SKIP LOCKED`,
+		model.PurchaseStatusPending,
+	).Scan(&purchaseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+
+	if err := releasePurchase(ctx, tx, purchaseID, model.PurchaseStatusExpired); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return true, nil
+}
+
+// RunPurchaseExpirer menjalankan ReleaseExpiredPurchases setiap interval sampai ctx dibatalkan.
+// Blocking, sama seperti RunOrphanSweeper.
+func (s *Service) RunPurchaseExpirer(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Purchase expirer disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.ReleaseExpiredPurchases(ctx)
+			if err != nil {
+				log.Printf("Releasing expired purchases failed: %v", err)
+				continue
+			}
+			if released > 0 {
+				log.Printf("Released %d expired purchase(s)", released)
+			}
+		}
+	}
+}
+
+// lockPendingPurchase mengunci order milik userID yang masih pending.
+// expired true kalau reservasinya sudah lewat tapi belum dilepas.
+func lockPendingPurchase(ctx context.Context, tx pgx.Tx, purchaseID, userID uint) (bool, error) {
+	var ownerID uint
+	var status string
+	var expiresAt time.Time
+	err := tx.QueryRow(ctx, `SELECT "userId", status, "expiresAt" FROM purchase WHERE "purchaseId" = $1 FOR UPDATE`, purchaseID).
+		Scan(&ownerID, &status, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrPurchaseNotFound
+	} else if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	if ownerID != userID {
+		return false, ErrPurchaseNotFound
+	}
+
+	switch status {
+	case model.PurchaseStatusPending:
+		return !time.Now().Before(expiresAt), nil
+	case model.PurchaseStatusPaid:
+		return false, ErrPurchaseAlreadyPaid
+	default:
+		return false, ErrPurchaseClosed
+	}
+}
+
+// releasePurchase mengembalikan stok order pending dan mengubah statusnya.
+// Product dikunci urut productId seperti di CreatePurchase supaya tidak deadlock.
+func releasePurchase(ctx context.Context, tx pgx.Tx, purchaseID uint, status string) error {
+	rows, err := tx.Query(ctx,
+		`SELECT "productId", qty FROM "purchaseItem" WHERE "purchaseId" = $1 ORDER BY "productId"`, purchaseID)
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	var items []model.PurchaseItem
+	for rows.Next() {
+		var item model.PurchaseItem
+		if err := rows.Scan(&item.ProductId, &item.Qty); err != nil {
+			rows.Close()
+			return fmt.Errorf("database error: %v", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+
+	for _, item := range items {
+		_, err := tx.Exec(ctx, `UPDATE product SET qty = qty + $1 WHERE "productId" = $2`, item.Qty, item.ProductId)
+		if err != nil {
+			return fmt.Errorf("failed to release stock: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE purchase SET status = $1, "cancelledAt" = now() WHERE "purchaseId" = $2`,
+		status, purchaseID)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase: %v", err)
+	}
+	return nil
+}
+
+func getPurchase(ctx context.Context, tx pgx.Tx, purchaseID uint) (*model.Purchase, error) {
+	var purchase model.Purchase
+	err := tx.QueryRow(ctx,
+		`SELECT "purchaseId", "userId", "senderName", "senderContactType", "senderContactDetail", "totalPrice", status,
+                "createdAt", "expiresAt", "paidAt", "cancelledAt"
+         FROM purchase WHERE "purchaseId" = $1`, purchaseID,
+	).Scan(&purchase.PurchaseId, &purchase.UserId, &purchase.SenderName, &purchase.SenderContactType,
+		&purchase.SenderContactDetail, &purchase.TotalPrice, &purchase.Status, &purchase.CreatedAt, &purchase.ExpiresAt,
+		&purchase.PaidAt, &purchase.CancelledAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPurchaseNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT i."productId", i."sellerId", i.name, i.category, i.sku, i.price, i.qty, i."fileId",
+                COALESCE(f."fileUri", ''), COALESCE(f."fileThumbnailUri", '')
+         FROM "purchaseItem" i
+         LEFT JOIN file f ON f."fileId" = i."fileId"
+         WHERE i."purchaseId" = $1
+         ORDER BY i."productId"`, purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.PurchaseItem
+		err := rows.Scan(&item.ProductId, &item.SellerId, &item.Name, &item.Category, &item.Sku, &item.Price,
+			&item.Qty, &item.FileId, &item.FileUri, &item.FileThumbnailUri)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %v", err)
+		}
+		purchase.Items = append(purchase.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+
+	purchase.Payments, err = getPaymentDetails(ctx, tx, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+func getPaymentDetails(ctx context.Context, tx pgx.Tx, purchaseID uint) ([]model.PaymentDetail, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT "sellerId", "bankAccountName", "bankAccountHolder", "bankAccountNumber", "totalPrice", "fileId"
+         FROM "purchasePayment" WHERE "purchaseId" = $1 ORDER BY "sellerId"`,
+		purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer rows.Close()
+
+	var payments []model.PaymentDetail
+	for rows.Next() {
+		var payment model.PaymentDetail
+		err := rows.Scan(&payment.SellerId, &payment.BankAccountName, &payment.BankAccountHolder,
+			&payment.BankAccountNumber, &payment.TotalPrice, &payment.FileId)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %v", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	return payments, nil
+}
+
+// fillSellerBankAccounts mengisi rekening tujuan transfer dari profil tiap seller
+func fillSellerBankAccounts(ctx context.Context, tx pgx.Tx, payments []model.PaymentDetail) error {
+	for i := range payments {
+		var name, holder, number *string
+		err := tx.QueryRow(ctx,
+			`SELECT "bankAccountName", "bankAccountHolder", "bankAccountNumber" FROM "userProfile" WHERE "userId" = $1`,
+			payments[i].SellerId,
+		).Scan(&name, &holder, &number)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("database error: %v", err)
+		}
+		if name == nil || holder == nil || number == nil || *name == "" || *holder == "" || *number == "" {
+			return fmt.Errorf("%w: seller %d", ErrSellerBankMissing, payments[i].SellerId)
+		}
+
+		payments[i].BankAccountName = *name
+		payments[i].BankAccountHolder = *holder
+		payments[i].BankAccountNumber = *number
+	}
+	return nil
+}
+
+// mergePurchaseItems menggabungkan productId yang sama dan mengurutkannya berdasarkan productId
+func mergePurchaseItems(items []model.PurchaseItem) []model.PurchaseItem {
+	qty := map[uint]int{}
+	for _, item := range items {
+		qty[item.ProductId] += item.Qty
+	}
+
+	merged := make([]model.PurchaseItem, 0, len(qty))
+	for productID, q := range qty {
+		merged = append(merged, model.PurchaseItem{ProductId: productID, Qty: q})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ProductId < merged[j].ProductId })
+	return merged
+}
+
+func paymentDetailsFromTotals(totals map[uint]int) []model.PaymentDetail {
+	payments := make([]model.PaymentDetail, 0, len(totals))
+	for sellerID, total := range totals {
+		payments = append(payments, model.PaymentDetail{SellerId: sellerID, TotalPrice: total})
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].SellerId < payments[j].SellerId })
+	return payments
+}
@@ -0,0 +1,245 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sprint3/internal/model"
+	"sprint3/internal/service"
+	"sync"
+	"testing"
+)
+
+func checkout(svc *service.Service, buyerID, productID uint, qty int) (*model.Purchase, error) {
+	return svc.CreatePurchase(&model.Purchase{
+		UserId:              buyerID,
+		SenderName:          "Buyer",
+		SenderContactType:   model.ContactTypeEmail,
+		SenderContactDetail: "buyer@example.com",
+		Items:               []model.PurchaseItem{{ProductId: productID, Qty: qty}},
+	})
+}
+
+// stock qty dan sold product saat ini
+func stock(t *testing.T, svc *service.Service, productID uint) (int, int) {
+	t.Helper()
+	products, err := svc.GetProducts(service.ProductFilter{ProductId: &productID, Limit: 1})
+	if err != nil || len(products) != 1 {
+		t.Fatalf("GetProducts(%d) = %v, %v", productID, products, err)
+	}
+	return products[0].Qty, products[0].Sold
+}
+
+func purchaseStatus(t *testing.T, db *pgxpool.Pool, purchaseID uint) string {
+	t.Helper()
+	var status string
+	if err := db.QueryRow(context.Background(), `SELECT status FROM purchase WHERE "purchaseId" = $1`, purchaseID).Scan(&status); err != nil {
+		t.Fatalf("read purchase status: %v", err)
+	}
+	return status
+}
+
+func TestCreatePurchaseConcurrentCheckoutsDoNotOversell(t *testing.T) {
+	svc, _ := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+	buyerID := createSeller(t, svc, "buyer@example.com")
+	product := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+
+	const checkouts = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, checkouts)
+	for i := 0; i < checkouts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := checkout(svc, buyerID, product.ProductId, 1)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, service.ErrInsufficientStock) {
+			t.Fatalf("CreatePurchase error = %v", err)
+		}
+	}
+	if succeeded != 5 {
+		t.Fatalf("%d checkouts succeeded, want 5", succeeded)
+	}
+	if qty, sold := stock(t, svc, product.ProductId); qty != 0 || sold != 0 {
+		t.Fatalf("qty = %d, sold = %d, want 0 and 0 before payment", qty, sold)
+	}
+}
+
+func TestCancelPurchaseReleasesStock(t *testing.T) {
+	svc, db := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+	buyerID := createSeller(t, svc, "buyer@example.com")
+	product := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+
+	purchase, err := checkout(svc, buyerID, product.ProductId, 3)
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	if qty, _ := stock(t, svc, product.ProductId); qty != 2 {
+		t.Fatalf("qty after checkout = %d, want 2", qty)
+	}
+
+	if _, err := svc.CancelPurchase(purchase.PurchaseId, sellerID); !errors.Is(err, service.ErrPurchaseNotFound) {
+		t.Fatalf("cancel by another user error = %v, want ErrPurchaseNotFound", err)
+	}
+	cancelled, err := svc.CancelPurchase(purchase.PurchaseId, buyerID)
+	if err != nil {
+		t.Fatalf("CancelPurchase: %v", err)
+	}
+	if cancelled.Status != model.PurchaseStatusCancelled || cancelled.CancelledAt == nil {
+		t.Fatalf("cancelled purchase = %+v, want status cancelled with cancelledAt", cancelled)
+	}
+	if qty, _ := stock(t, svc, product.ProductId); qty != 5 {
+		t.Fatalf("qty after cancel = %d, want 5", qty)
+	}
+
+	// Order yang sudah dibatalkan tidak bisa dibatalkan lagi atau dibayar, stok tidak dikembalikan dua kali
+	if _, err := svc.CancelPurchase(purchase.PurchaseId, buyerID); !errors.Is(err, service.ErrPurchaseClosed) {
+		t.Fatalf("second cancel error = %v, want ErrPurchaseClosed", err)
+	}
+	proof := addFile(t, svc, buyerID, model.FileVisibilityPrivate)
+	if _, err := svc.PayPurchase(purchase.PurchaseId, buyerID, []uint{proof}); !errors.Is(err, service.ErrPurchaseClosed) {
+		t.Fatalf("pay after cancel error = %v, want ErrPurchaseClosed", err)
+	}
+	if qty, _ := stock(t, svc, product.ProductId); qty != 5 {
+		t.Fatalf("qty after second cancel = %d, want 5", qty)
+	}
+	if status := purchaseStatus(t, db, purchase.PurchaseId); status != model.PurchaseStatusCancelled {
+		t.Fatalf("status = %s, want cancelled", status)
+	}
+}
+
+func TestExpiredPurchasesReleaseStock(t *testing.T) {
+	svc, db := newDBService(t)
+	ctx := context.Background()
+	sellerID := createSeller(t, svc, "seller@example.com")
+	buyerID := createSeller(t, svc, "buyer@example.com")
+	product := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+
+	expiring, err := checkout(svc, buyerID, product.ProductId, 2)
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	unpaid, err := checkout(svc, buyerID, product.ProductId, 1)
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	pending, err := checkout(svc, buyerID, product.ProductId, 1)
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	_, err = db.Exec(ctx, `UPDATE purchase SET "expiresAt" = now() - interval '1 minute' WHERE "purchaseId" = ANY($1)`,
+		[]int64{int64(expiring.PurchaseId), int64(unpaid.PurchaseId)})
+	if err != nil {
+		t.Fatalf("expire purchases: %v", err)
+	}
+
+	// Pembayaran order yang lewat expiresAt ditolak dan stoknya langsung dilepas
+	proof := addFile(t, svc, buyerID, model.FileVisibilityPrivate)
+	if _, err := svc.PayPurchase(unpaid.PurchaseId, buyerID, []uint{proof}); !errors.Is(err, service.ErrPurchaseClosed) {
+		t.Fatalf("pay expired purchase error = %v, want ErrPurchaseClosed", err)
+	}
+	if qty, _ := stock(t, svc, product.ProductId); qty != 2 {
+		t.Fatalf("qty after paying an expired purchase = %d, want 2", qty)
+	}
+
+	released, err := svc.ReleaseExpiredPurchases(ctx)
+	if err != nil {
+		t.Fatalf("ReleaseExpiredPurchases: %v", err)
+	}
+	if released != 1 {
+		t.Fatalf("released %d purchase(s), want 1", released)
+	}
+	if qty, _ := stock(t, svc, product.ProductId); qty != 4 {
+		t.Fatalf("qty after release = %d, want 4", qty)
+	}
+	for _, id := range []uint{expiring.PurchaseId, unpaid.PurchaseId} {
+		if status := purchaseStatus(t, db, id); status != model.PurchaseStatusExpired {
+			t.Fatalf("purchase %d status = %s, want expired", id, status)
+		}
+	}
+	if status := purchaseStatus(t, db, pending.PurchaseId); status != model.PurchaseStatusPending {
+		t.Fatalf("unexpired purchase status = %s, want pending", status)
+	}
+
+	if released, err := svc.ReleaseExpiredPurchases(ctx); err != nil || released != 0 {
+		t.Fatalf("second ReleaseExpiredPurchases = %d, %v, want 0", released, err)
+	}
+}
+
+func TestPayPurchase(t *testing.T) {
+	svc, db := newDBService(t)
+	sellerID := createSeller(t, svc, "seller@example.com")
+	buyerID := createSeller(t, svc, "buyer@example.com")
+	product := createProduct(t, svc, sellerID, "SKU-1", 5, 1000)
+
+	purchase, err := checkout(svc, buyerID, product.ProductId, 2)
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	if len(purchase.Payments) != 1 || purchase.Payments[0].TotalPrice != 2000 {
+		t.Fatalf("payments = %+v, want one payment of 2000", purchase.Payments)
+	}
+
+	publicProof := addFile(t, svc, buyerID, model.FileVisibilityPublic)
+	if _, err := svc.PayPurchase(purchase.PurchaseId, buyerID, []uint{publicProof}); !errors.Is(err, service.ErrPaymentProofPublic) {
+		t.Fatalf("pay with public proof error = %v, want ErrPaymentProofPublic", err)
+	}
+	proof := addFile(t, svc, buyerID, model.FileVisibilityPrivate)
+	if _, err := svc.PayPurchase(purchase.PurchaseId, buyerID, []uint{proof, proof}); !errors.Is(err, service.ErrPaymentProofMismatch) {
+		t.Fatalf("pay with two proofs error = %v, want ErrPaymentProofMismatch", err)
+	}
+	sellerProof := addFile(t, svc, sellerID, model.FileVisibilityPrivate)
+	if _, err := svc.PayPurchase(purchase.PurchaseId, buyerID, []uint{sellerProof}); !errors.Is(err, service.ErrFileNotFound) {
+		t.Fatalf("pay with another user's file error = %v, want ErrFileNotFound", err)
+	}
+
+	// Dua pembayaran bersamaan, hanya satu yang berhasil dan sold hanya bertambah sekali
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.PayPurchase(purchase.PurchaseId, buyerID, []uint{proof})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	paid, alreadyPaid := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			paid++
+		case errors.Is(err, service.ErrPurchaseAlreadyPaid):
+			alreadyPaid++
+		default:
+			t.Fatalf("PayPurchase error = %v", err)
+		}
+	}
+	if paid != 1 || alreadyPaid != 1 {
+		t.Fatalf("paid = %d, already paid = %d, want 1 and 1", paid, alreadyPaid)
+	}
+
+	if qty, sold := stock(t, svc, product.ProductId); qty != 3 || sold != 2 {
+		t.Fatalf("qty = %d, sold = %d, want 3 and 2", qty, sold)
+	}
+	if status := purchaseStatus(t, db, purchase.PurchaseId); status != model.PurchaseStatusPaid {
+		t.Fatalf("status = %s, want paid", status)
+	}
+	if _, err := svc.CancelPurchase(purchase.PurchaseId, buyerID); !errors.Is(err, service.ErrPurchaseAlreadyPaid) {
+		t.Fatalf("cancel paid purchase error = %v, want ErrPurchaseAlreadyPaid", err)
+	}
+}
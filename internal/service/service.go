@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"log"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/storage"
+	"sprint3/pkg/config"
+	"sync"
+	"time"
+)
+
+// backgroundTimeout batas waktu satu pekerjaan background, misal mengirim pesan lewat notifier
+const backgroundTimeout = time.Minute
+
+// Service semua use case aplikasi beserta dependensinya. Tidak ada state di level package,
+// jadi beberapa instance (misal satu per test) tidak saling berbagi pool, storage atau config.
+type Service struct {
+	cfg      *config.Config
+	db       *pgxpool.Pool
+	repos    repository.Repositories
+	storage  *storage.Client
+	notifier notifier.Notifier
+	// background pekerjaan yang berjalan setelah request selesai, ditunggu lewat Wait
+	background sync.WaitGroup
+}
+
+// New membuat Service. repos biasanya repository.NewPostgres(db). Hanya alur user (akun, profil, file,
+// session, reset password, verifikasi) yang lewat repos, jadi hanya alur itu yang bisa dites dengan
+// repository.NewMemory() dan db nil. Product, purchase, admin dan orphan sweeper menjalankan SQL langsung
+// di db dan akan panic kalau db nil.
+func New(cfg *config.Config, db *pgxpool.Pool, repos repository.Repositories, store *storage.Client, notify notifier.Notifier) *Service {
+	return &Service{cfg: cfg, db: db, repos: repos, storage: store, notifier: notify}
+}
+
+// Wait menunggu semua pekerjaan background selesai, dipanggil sebelum database ditutup
+func (s *Service) Wait() {
+	s.background.Wait()
+}
+
+// goBackground menjalankan fn di luar request dengan context ber-timeout, error-nya hanya di-log
+func (s *Service) goBackground(name string, fn func(ctx context.Context) error) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			log.Printf("Background %s failed: %v", name, err)
+		}
+	}()
+}
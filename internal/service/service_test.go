@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sprint3/internal/model"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"sprint3/internal/service"
+	"sprint3/internal/storage"
+	"sprint3/internal/testutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier menyimpan pesan yang dikirim supaya test bisa membaca token/OTP-nya
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []notifier.Message
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg notifier.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.messages)
+}
+
+// secret mengambil kata setelah prefix di pesan terakhir, misal token reset atau kode OTP
+func (n *recordingNotifier) secret(t *testing.T, prefix string) string {
+	t.Helper()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.messages) == 0 {
+		t.Fatal("no message sent")
+	}
+	body := n.messages[len(n.messages)-1].Body
+	_, rest, ok := strings.Cut(body, prefix)
+	if !ok {
+		t.Fatalf("message %q does not contain %q", body, prefix)
+	}
+	return strings.Fields(rest)[0]
+}
+
+// newTestService Service dengan repository dan storage di memori, tanpa database
+func newTestService(t *testing.T) (*service.Service, *recordingNotifier) {
+	t.Helper()
+	notify := &recordingNotifier{}
+	cfg := testutil.Config()
+	return service.New(cfg, nil, repository.NewMemory(), testutil.MemoryStorage(cfg), notify), notify
+}
+
+// newDBService Service dengan Postgres dari TEST_DATABASE_URL, untuk product, purchase dan admin
+func newDBService(t *testing.T) (*service.Service, *pgxpool.Pool) {
+	t.Helper()
+	db := testutil.Database(t)
+	cfg := testutil.Config()
+	return service.New(cfg, db, repository.NewPostgres(db), testutil.MemoryStorage(cfg), &recordingNotifier{}), db
+}
+
+// createSeller user baru dengan rekening bank, jadi bisa menerima pembayaran
+func createSeller(t *testing.T, svc *service.Service, email string) uint {
+	t.Helper()
+	user, err := svc.RegisterUserEmail(email, "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	bank, holder, number := "BCA", "Seller", "1234567890"
+	_, err = svc.UpdateUserProfile(user.Id, service.UserProfileUpdate{
+		BankAccountName:   &bank,
+		BankAccountHolder: &holder,
+		BankAccountNumber: &number,
+	})
+	if err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+	return user.Id
+}
+
+// addFile mencatat file milik userID tanpa mengunggah object
+func addFile(t *testing.T, svc *service.Service, userID uint, visibility string) uint {
+	t.Helper()
+	key := fmt.Sprintf("%s%d-%d.png", storage.PublicPrefix, userID, time.Now().UnixNano())
+	file, err := svc.AddFile(&model.File{
+		UserId:       userID,
+		URI:          "http://files.test/" + key,
+		ThumbnailURI: "http://files.test/" + key,
+		Renditions:   []model.FileRendition{},
+		ObjectKeys:   []string{key},
+		Visibility:   visibility,
+	})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	return uint(file.ID)
+}
+
+func createProduct(t *testing.T, svc *service.Service, sellerID uint, sku string, qty, price int) *model.Product {
+	t.Helper()
+	product, err := svc.CreateProduct(&model.Product{
+		Name:     "Product " + sku,
+		Category: "Food",
+		Qty:      qty,
+		Price:    price,
+		Sku:      sku,
+		FileId:   addFile(t, svc, sellerID, model.FileVisibilityPublic),
+		UserId:   sellerID,
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	return product
+}
+
+// verifyContact memverifikasi kontak user lewat OTP yang dikirim ke notifier
+func verifyContact(t *testing.T, svc *service.Service, notify *recordingNotifier, userID uint, channel string) {
+	t.Helper()
+	if err := svc.SendVerificationCode(userID, channel); err != nil {
+		t.Fatalf("SendVerificationCode: %v", err)
+	}
+	if err := svc.VerifyCode(userID, channel, notify.secret(t, "Kode verifikasi Anda: ")); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+}
+
+func TestCheckDependenciesWithoutDatabase(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	statuses := svc.CheckDependencies(context.Background(), time.Second)
+	if len(statuses) != 1 || statuses[0].Name != "storage" || statuses[0].Err != nil {
+		t.Fatalf("statuses = %+v, want only a healthy storage check", statuses)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"sprint3/internal/model"
+	"sprint3/internal/repository"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token already used")
+)
+
+// CreateSession membuat session baru setelah login/register beserta refresh token pertamanya
+func (s *Service) CreateSession(userID uint) (*model.Session, error) {
+	session := &model.Session{ID: uuid.New().String(), UserId: userID}
+	token, err := s.newRefreshToken(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repos.Sessions.Create(context.Background(), session, hashToken(token)); err != nil {
+		return nil, err
+	}
+	session.RefreshToken = token
+	return session, nil
+}
+
+// RotateRefreshToken menukar refresh token dengan refresh token baru di session yang sama.
+// Refresh token yang dipakai dua kali dianggap bocor, session-nya langsung dicabut.
+func (s *Service) RotateRefreshToken(refreshToken string) (*model.User, *model.Session, error) {
+	ctx := context.Background()
+
+	next := &model.Session{}
+	token, err := s.newRefreshToken(next)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := s.repos.Sessions.Rotate(ctx, hashToken(refreshToken), hashToken(token), next.RefreshExpiresAt)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrInvalidRefreshToken
+	} else if errors.Is(err, repository.ErrReused) {
+		log.Println("Refresh token reuse detected, session revoked")
+		return nil, nil, ErrRefreshTokenReused
+	} else if err != nil {
+		return nil, nil, err
+	}
+	session.RefreshToken = token
+
+	// Data user dibaca ulang supaya token baru memuat email/phone dan role terbaru
+	user, err := s.repos.Users.FindByID(ctx, session.UserId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if user.SuspendedAt != nil {
+		return nil, nil, ErrUserSuspended
+	}
+	return user, session, nil
+}
+
+// RevokeSession logout dari satu session, jti access token yang sedang dipakai ikut dicabut
+func (s *Service) RevokeSession(userID uint, sessionID, jti string, tokenExpiresAt time.Time) error {
+	ctx := context.Background()
+	if err := s.repos.Sessions.Revoke(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	return s.repos.Sessions.RevokeToken(ctx, jti, tokenExpiresAt)
+}
+
+// RevokeAllSessions logout dari semua device milik userID, mengembalikan jumlah session yang dicabut
+func (s *Service) RevokeAllSessions(userID uint) (int64, error) {
+	return s.repos.Sessions.RevokeAll(context.Background(), userID, "")
+}
+
+// IsTokenActive memastikan session access token belum logout dan jti-nya belum dicabut
+func (s *Service) IsTokenActive(sessionID, jti string) (bool, error) {
+	return s.repos.Sessions.IsActive(context.Background(), sessionID, jti)
+}
+
+// newRefreshToken membuat refresh token acak untuk session dan mengisi masa berlakunya.
+// Yang disimpan repository hanya hash SHA-256-nya.
+func (s *Service) newRefreshToken(session *model.Session) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	session.RefreshExpiresAt = time.Now().Add(s.cfg.RefreshTokenTTL)
+	return token, nil
+}
+
+// randomToken token acak 256 bit untuk refresh token dan reset password
+func randomToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// hashToken token hanya disimpan sebagai hash SHA-256 supaya bocornya database tidak membocorkan token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"errors"
+	"sprint3/internal/service"
+	"testing"
+	"time"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	svc, _ := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	session, err := svc.CreateSession(user.Id)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	got, rotated, err := svc.RotateRefreshToken(session.RefreshToken)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if got.Id != user.Id || rotated.ID != session.ID || rotated.RefreshToken == session.RefreshToken {
+		t.Fatalf("rotated session = %+v for user %d, want a new token in session %s", rotated, got.Id, session.ID)
+	}
+
+	// Token lama yang dipakai lagi dianggap bocor, session-nya dicabut
+	if _, _, err := svc.RotateRefreshToken(session.RefreshToken); !errors.Is(err, service.ErrRefreshTokenReused) {
+		t.Fatalf("reused token error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := svc.RotateRefreshToken(rotated.RefreshToken); !errors.Is(err, service.ErrInvalidRefreshToken) {
+		t.Fatalf("token of revoked session error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if active, _ := svc.IsTokenActive(session.ID, "jti"); active {
+		t.Fatal("session still active after refresh token reuse")
+	}
+
+	if _, _, err := svc.RotateRefreshToken("unknown"); !errors.Is(err, service.ErrInvalidRefreshToken) {
+		t.Fatalf("unknown token error = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	svc, _ := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	current, _ := svc.CreateSession(user.Id)
+	other, _ := svc.CreateSession(user.Id)
+
+	if err := svc.RevokeSession(user.Id, current.ID, "jti-1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if active, _ := svc.IsTokenActive(current.ID, "jti-2"); active {
+		t.Fatal("revoked session still active")
+	}
+	if active, _ := svc.IsTokenActive(other.ID, "jti-1"); active {
+		t.Fatal("revoked jti still active in another session")
+	}
+	if active, _ := svc.IsTokenActive(other.ID, "jti-2"); !active {
+		t.Fatal("other session revoked by single logout")
+	}
+
+	revoked, err := svc.RevokeAllSessions(user.Id)
+	if err != nil || revoked != 1 {
+		t.Fatalf("RevokeAllSessions = %d, %v, want 1 session", revoked, err)
+	}
+}
@@ -7,8 +7,7 @@ import (
 	"github.com/jackc/pgx/v4"
 	"golang.org/x/crypto/bcrypt"
 	"sprint3/internal/model"
-	"sprint3/pkg/database"
-	"time"
+	"sprint3/internal/repository"
 )
 
 var (
@@ -17,18 +16,29 @@ var (
 	ErrInvalidPassword    = errors.New("invalid password")
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrPhoneAlreadyExists = errors.New("phone already exists")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrFileNotFound       = errors.New("file not found")
+	ErrUserSuspended      = errors.New("user is suspended")
+	ErrContactAlreadySet  = errors.New("contact is already set")
 )
 
-func RegisterUserEmail(email, password string) (*model.User, error) {
-	db := database.GetDBPool()
+func (s *Service) RegisterUserEmail(email, password string) (*model.User, error) {
+	return s.registerUser(repository.ContactEmail, email, password, ErrEmailAlreadyExists)
+}
+
+func (s *Service) RegisterUserPhone(phone, password string) (*model.User, error) {
+	return s.registerUser(repository.ContactPhone, phone, password, ErrPhoneAlreadyExists)
+}
 
-	// Check if email exists
-	var existingUser model.User
-	err := db.QueryRow(context.Background(), "SELECT email FROM public.user WHERE email = $1", email).Scan(&existingUser.Email)
-	if err == nil {
-		return nil, ErrEmailAlreadyExists
-	} else if !errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("database error: %v", err)
+// registerUser membuat user baru yang login dengan email atau phone, profilnya ikut dibuat
+func (s *Service) registerUser(contact, value, password string, errConflict error) (*model.User, error) {
+	ctx := context.Background()
+
+	// Check if email/phone exists
+	if _, err := s.repos.Users.FindByContact(ctx, contact, value); err == nil {
+		return nil, errConflict
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
 	}
 
 	// Hash password
@@ -37,112 +47,159 @@ func RegisterUserEmail(email, password string) (*model.User, error) {
 		return nil, fmt.Errorf("failed to hash password: %v", err)
 	}
 
-	// Insert user into database and get the generated ID
-	var userID uint
-	err = db.QueryRow(context.Background(),
-		`INSERT INTO public.user (email, password, "createdAt") 
-         VALUES ($1, $2, $3) 
-         RETURNING "userId"`,
-		email, string(hashedPassword), time.Now(),
-	).Scan(&userID)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to register user: %v", err)
+	user := &model.User{Password: string(hashedPassword), Roles: model.DefaultRoles}
+	if contact == repository.ContactEmail {
+		user.Email = &value
+	} else {
+		user.Phone = &value
 	}
+	// Dua register bersamaan bisa lolos pengecekan di atas, unique constraint yang jadi penentu
+	if err := s.repos.Users.Create(ctx, user); errors.Is(err, repository.ErrDuplicate) {
+		return nil, errConflict
+	} else if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
-	_, err = db.Exec(context.Background(), `INSERT INTO "userProfile" (email, "userId") VALUES ($1, $2)`,
-		email, userID)
-	return &model.User{
-		Email:    &email,
-		Password: string(hashedPassword),
-		Id:       userID,
-	}, nil
+// AuthenticateEmail login dengan email. ip dipakai untuk membatasi percobaan gagal per IP.
+func (s *Service) AuthenticateEmail(email, password, ip string) (*model.User, error) {
+	return s.authenticate(repository.ContactEmail, email, password, ip, ErrEmailNotFound)
 }
 
-func RegisterUserPhone(phone, password string) (*model.User, error) {
-	db := database.GetDBPool()
+// AuthenticatePhone login dengan nomor telepon
+func (s *Service) AuthenticatePhone(phone, password, ip string) (*model.User, error) {
+	return s.authenticate(repository.ContactPhone, phone, password, ip, ErrPhoneNotFound)
+}
 
-	// Check if phone exists
-	var existingUser model.User
-	err := db.QueryRow(context.Background(), "SELECT phone FROM public.user WHERE phone = $1", phone).Scan(&existingUser.Phone)
-	if err == nil {
-		return nil, ErrPhoneAlreadyExists
-	} else if !errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("database error: %v", err)
-	}
+// dummyPasswordHash dipakai saat akun tidak ditemukan supaya waktu respons sama dengan password salah
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %v", err)
+// authenticate mengecek lockout, lalu password. Percobaan gagal (termasuk akun yang tidak ada) dicatat per akun dan per IP.
+// contact diisi repository.ContactEmail/ContactPhone dari pemanggil di atas.
+func (s *Service) authenticate(contact, identifier, password, ip string, errNotFound error) (*model.User, error) {
+	ctx := context.Background()
+	guard := s.newLoginGuard(contact, identifier, ip)
+
+	if err := guard.check(ctx); err != nil {
+		return nil, err
 	}
 
-	// Insert user into database and get the generated ID
-	var userID uint
-	err = db.QueryRow(context.Background(),
-		`INSERT INTO public.user (phone, password, "createdAt") 
-         VALUES ($1, $2, $3) 
-         RETURNING "userId"`,
-		phone, string(hashedPassword), time.Now(),
-	).Scan(&userID)
+	user, err := s.repos.Users.FindByContact(ctx, contact, identifier)
+	if errors.Is(err, repository.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		if err := guard.recordFailure(ctx, nil); err != nil {
+			return nil, err
+		}
+		return nil, errNotFound
+	} else if err != nil {
+		return nil, err
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to register user: %v", err)
+	// Compare password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		if err := guard.recordFailure(ctx, &user.Id); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidPassword
 	}
 
-	_, err = db.Exec(context.Background(), `INSERT INTO "userProfile" (phone, "userId") VALUES ($1, $2)`,
-		phone, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to insert user profile: %v", err)
+	if err := guard.recordSuccess(ctx); err != nil {
+		return nil, err
 	}
+	// Dicek setelah password supaya status suspend tidak bocor ke orang yang tidak tahu password-nya
+	if user.SuspendedAt != nil {
+		return nil, ErrUserSuspended
+	}
+	return user, nil
+}
 
-	return &model.User{
-		Phone:    &phone,
-		Password: string(hashedPassword),
-		Id:       userID,
-	}, nil
+func (s *Service) GetUserProfile(userID uint) (*model.UserProfile, error) {
+	return s.getUserProfile(context.Background(), userID)
 }
 
-func AuthenticateEmail(email, password string) (*model.User, error) {
-	db := database.GetDBPool()
-	var user model.User
+// UserProfileUpdate berisi field profil yang ingin diubah. Field bernilai nil tidak diubah.
+type UserProfileUpdate = repository.ProfileUpdate
 
-	// Retrieve user by email, gunakan pointer untuk phone agar bisa menangani NULL
-	err := db.QueryRow(context.Background(), `SELECT "userId", email, password, phone FROM public.user WHERE email = $1`, email).
-		Scan(&user.Id, &user.Email, &user.Password, &user.Phone)
+func (s *Service) UpdateUserProfile(userID uint, update UserProfileUpdate) (*model.UserProfile, error) {
+	ctx := context.Background()
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, ErrEmailNotFound
-	} else if err != nil {
-		return nil, fmt.Errorf("database error: %v", err)
+	// Pastikan file yang direferensikan ada dan diunggah oleh user ini
+	if update.FileId != nil {
+		if _, err := s.getOwnedFile(ctx, *update.FileId, userID); err != nil {
+			return nil, err
+		}
 	}
 
-	// Compare password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, ErrInvalidPassword
+	if err := s.repos.Profiles.Update(ctx, userID, update); errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, err
 	}
+	return s.getUserProfile(ctx, userID)
+}
 
-	return &user, nil
+// LinkPhone menambahkan nomor telepon ke akun yang dibuat dengan email
+func (s *Service) LinkPhone(userID uint, phone string) (*model.UserProfile, error) {
+	return s.linkContact(userID, repository.ContactPhone, phone, ErrPhoneAlreadyExists)
 }
 
-func AuthenticatePhone(phone, password string) (*model.User, error) {
-	db := database.GetDBPool()
-	var user model.User
+// LinkEmail menambahkan email ke akun yang dibuat dengan nomor telepon
+func (s *Service) LinkEmail(userID uint, email string) (*model.UserProfile, error) {
+	return s.linkContact(userID, repository.ContactEmail, email, ErrEmailAlreadyExists)
+}
 
-	// Retrieve user by email, gunakan pointer untuk phone agar bisa menangani NULL
-	err := db.QueryRow(context.Background(), `SELECT "userId", email, password, phone FROM public.user WHERE phone = $1`, phone).
-		Scan(&user.Id, &user.Email, &user.Password, &user.Phone)
+// linkContact mengisi email/phone user dan profilnya yang masih kosong, kontak baru harus diverifikasi.
+// Kontak yang sudah terisi tidak bisa diganti lewat sini (ErrContactAlreadySet).
+func (s *Service) linkContact(userID uint, contact, value string, errConflict error) (*model.UserProfile, error) {
+	ctx := context.Background()
+
+	// Cek apakah sudah dipakai user lain
+	if existing, err := s.repos.Users.FindByContact(ctx, contact, value); err == nil && existing.Id != userID {
+		return nil, errConflict
+	} else if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, ErrEmailNotFound
+	// Dua request bersamaan bisa lolos pengecekan di atas, unique constraint yang jadi penentu
+	err := s.repos.Users.AddContact(ctx, userID, contact, value)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, errConflict
+	} else if errors.Is(err, repository.ErrAlreadySet) {
+		return nil, ErrContactAlreadySet
+	} else if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUserNotFound
 	} else if err != nil {
-		return nil, fmt.Errorf("database error: %v", err)
+		return nil, err
 	}
+	return s.getUserProfile(ctx, userID)
+}
 
-	// Compare password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, ErrInvalidPassword
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func (s *Service) getUserProfile(ctx context.Context, userID uint) (*model.UserProfile, error) {
+	profile, err := s.repos.Profiles.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if profile.FileId == nil {
+		return profile, nil
 	}
 
-	return &user, nil
+	file, err := s.repos.Files.FindByID(ctx, *profile.FileId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return profile, nil
+	} else if err != nil {
+		return nil, err
+	}
+	// Foto profil private hanya dikembalikan sebagai signed URL
+	if err := s.signFileURLs(ctx, file); err != nil {
+		return nil, err
+	}
+	profile.FileUri, profile.FileThumbnailUri = &file.URI, &file.ThumbnailURI
+	return profile, nil
 }
@@ -0,0 +1,208 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sprint3/internal/model"
+	"sprint3/internal/repository"
+	"sprint3/internal/service"
+	"sprint3/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestRegisterAndAuthenticate(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	if _, err := svc.RegisterUserEmail("buyer@example.com", "password456"); !errors.Is(err, service.ErrEmailAlreadyExists) {
+		t.Fatalf("duplicate register error = %v, want ErrEmailAlreadyExists", err)
+	}
+
+	got, err := svc.AuthenticateEmail("buyer@example.com", "password123", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("AuthenticateEmail: %v", err)
+	}
+	if got.Id != user.Id {
+		t.Fatalf("authenticated user %d, want %d", got.Id, user.Id)
+	}
+
+	if _, err := svc.AuthenticateEmail("buyer@example.com", "wrong", "10.0.0.1"); !errors.Is(err, service.ErrInvalidPassword) {
+		t.Fatalf("wrong password error = %v, want ErrInvalidPassword", err)
+	}
+	if _, err := svc.AuthenticateEmail("nobody@example.com", "password123", "10.0.0.1"); !errors.Is(err, service.ErrEmailNotFound) {
+		t.Fatalf("unknown email error = %v, want ErrEmailNotFound", err)
+	}
+}
+
+func TestAuthenticateLocksAfterMaxFailures(t *testing.T) {
+	svc, _ := newTestService(t)
+	if _, err := svc.RegisterUserPhone("+628111", "password123"); err != nil {
+		t.Fatalf("RegisterUserPhone: %v", err)
+	}
+
+	// testConfig mengunci setelah 3 kali gagal
+	for i := 0; i < 3; i++ {
+		if _, err := svc.AuthenticatePhone("+628111", "wrong", "10.0.0.1"); !errors.Is(err, service.ErrInvalidPassword) {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidPassword", i+1, err)
+		}
+	}
+
+	_, err := svc.AuthenticatePhone("+628111", "password123", "10.0.0.1")
+	var locked *service.LoginLockedError
+	if !errors.As(err, &locked) || !errors.Is(err, service.ErrLoginLocked) {
+		t.Fatalf("error after lockout = %v, want LoginLockedError", err)
+	}
+	if locked.RetryAfter <= 0 {
+		t.Fatalf("RetryAfter = %s, want positive", locked.RetryAfter)
+	}
+}
+
+func TestPruneLoginAttempts(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		checkPruneLoginAttempts(t, nil, repository.NewMemory())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		db := testutil.Database(t)
+		checkPruneLoginAttempts(t, db, repository.NewPostgres(db))
+	})
+}
+
+func checkPruneLoginAttempts(t *testing.T, db *pgxpool.Pool, repos repository.Repositories) {
+	cfg := testutil.Config()
+	// Postgres menghitung window dalam detik, jadi window terkecil yang bisa dites 1 detik
+	cfg.LoginFailureWindow = time.Second
+	cfg.LoginLockoutBase = 100 * time.Millisecond
+	cfg.LoginLockoutMax = 100 * time.Millisecond
+	svc := service.New(cfg, db, repos, testutil.MemoryStorage(cfg), &recordingNotifier{})
+	ctx := context.Background()
+	if _, err := svc.RegisterUserPhone("+628111", "password123"); err != nil {
+		t.Fatalf("RegisterUserPhone: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		svc.AuthenticatePhone("+628111", "wrong", "10.0.0.1")
+	}
+	if pruned, err := svc.PruneLoginAttempts(ctx); err != nil || pruned != 0 {
+		t.Fatalf("PruneLoginAttempts inside window = %d, %v, want 0", pruned, err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := svc.AuthenticatePhone("+628999", "wrong", "10.0.0.2"); !errors.Is(err, service.ErrPhoneNotFound) {
+		t.Fatalf("unknown phone error = %v, want ErrPhoneNotFound", err)
+	}
+
+	// Hanya key akun dan IP yang gagal 1,5 detik lalu yang dihapus, percobaan barusan tetap dihitung
+	if pruned, err := svc.PruneLoginAttempts(ctx); err != nil || pruned != 2 {
+		t.Fatalf("PruneLoginAttempts after window = %d, %v, want 2", pruned, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.AuthenticatePhone("+628111", "wrong", "10.0.0.1"); !errors.Is(err, service.ErrInvalidPassword) {
+			t.Fatalf("attempt %d after prune error = %v, want ErrInvalidPassword", i+1, err)
+		}
+	}
+	if _, err := svc.AuthenticatePhone("+628111", "password123", "10.0.0.1"); err != nil {
+		t.Fatalf("login after pruned failures: %v", err)
+	}
+}
+
+func TestUpdateUserProfileClearsFields(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		svc, _ := newTestService(t)
+		checkUpdateUserProfileClearsFields(t, svc)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		svc, _ := newDBService(t)
+		checkUpdateUserProfileClearsFields(t, svc)
+	})
+}
+
+func checkUpdateUserProfileClearsFields(t *testing.T, svc *service.Service) {
+	userID := createSeller(t, svc, "seller@example.com")
+	fileID := addFile(t, svc, userID, model.FileVisibilityPublic)
+	if _, err := svc.UpdateUserProfile(userID, service.UserProfileUpdate{FileId: &fileID}); err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+
+	profile, err := svc.UpdateUserProfile(userID, service.UserProfileUpdate{ClearFileId: true, ClearBankAccountNumber: true})
+	if err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+	if profile.FileId != nil || profile.FileUri != nil || profile.BankAccountNumber != nil {
+		t.Fatalf("profile after clear = %+v, want file and bank number cleared", profile)
+	}
+	if profile.BankAccountName == nil || profile.BankAccountHolder == nil {
+		t.Fatalf("profile after clear = %+v, want bank name and holder kept", profile)
+	}
+
+	// Nilai baru didahulukan kalau field yang sama juga diminta dikosongkan
+	number := "9876543210"
+	profile, err = svc.UpdateUserProfile(userID, service.UserProfileUpdate{BankAccountNumber: &number, ClearBankAccountNumber: true})
+	if err != nil {
+		t.Fatalf("UpdateUserProfile: %v", err)
+	}
+	if profile.BankAccountNumber == nil || *profile.BankAccountNumber != number {
+		t.Fatalf("bank number = %v, want %s", profile.BankAccountNumber, number)
+	}
+}
+
+func TestLinkContact(t *testing.T) {
+	svc, _ := newTestService(t)
+	first, err := svc.RegisterUserEmail("first@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	second, err := svc.RegisterUserEmail("second@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+
+	profile, err := svc.LinkPhone(first.Id, "+628222")
+	if err != nil {
+		t.Fatalf("LinkPhone: %v", err)
+	}
+	if profile.Phone == nil || *profile.Phone != "+628222" || profile.PhoneVerified {
+		t.Fatalf("profile after link = %+v, want unverified phone +628222", profile)
+	}
+
+	if _, err := svc.LinkPhone(second.Id, "+628222"); !errors.Is(err, service.ErrPhoneAlreadyExists) {
+		t.Fatalf("linking a taken phone error = %v, want ErrPhoneAlreadyExists", err)
+	}
+	if _, err := svc.AuthenticatePhone("+628222", "password123", "10.0.0.1"); err != nil {
+		t.Fatalf("login with linked phone: %v", err)
+	}
+}
+
+func TestLinkContactRejectsExistingContact(t *testing.T) {
+	svc, _ := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	if _, err := svc.LinkPhone(user.Id, "+628111"); err != nil {
+		t.Fatalf("LinkPhone: %v", err)
+	}
+
+	// Kontak yang sudah terisi tidak boleh ditimpa, termasuk email yang dipakai saat register
+	if _, err := svc.LinkEmail(user.Id, "attacker@example.com"); !errors.Is(err, service.ErrContactAlreadySet) {
+		t.Fatalf("overwrite email error = %v, want ErrContactAlreadySet", err)
+	}
+	if _, err := svc.LinkPhone(user.Id, "+628999"); !errors.Is(err, service.ErrContactAlreadySet) {
+		t.Fatalf("overwrite phone error = %v, want ErrContactAlreadySet", err)
+	}
+
+	profile, err := svc.GetUserProfile(user.Id)
+	if err != nil {
+		t.Fatalf("GetUserProfile: %v", err)
+	}
+	if *profile.Email != "buyer@example.com" || *profile.Phone != "+628111" {
+		t.Fatalf("profile = %s / %s, want the original contacts", *profile.Email, *profile.Phone)
+	}
+	if _, err := svc.AuthenticateEmail("attacker@example.com", "password123", "10.0.0.1"); !errors.Is(err, service.ErrEmailNotFound) {
+		t.Fatalf("login with rejected email error = %v, want ErrEmailNotFound", err)
+	}
+}
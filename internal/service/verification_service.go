@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"sprint3/internal/notifier"
+	"sprint3/internal/repository"
+	"time"
+)
+
+var (
+	ErrContactNotSet          = errors.New("contact is not set")
+	ErrContactAlreadyVerified = errors.New("contact already verified")
+	ErrOTPTooSoon             = errors.New("verification code was sent recently")
+	ErrInvalidOTP             = errors.New("invalid verification code")
+	ErrOTPExpired             = errors.New("verification code expired")
+	ErrOTPAttemptsExceeded    = errors.New("too many verification attempts")
+)
+
+const otpDigits = 6
+
+// verificationContacts kontak user yang diverifikasi per channel
+var verificationContacts = map[string]string{
+	notifier.ChannelEmail: repository.ContactEmail,
+	notifier.ChannelSMS:   repository.ContactPhone,
+}
+
+// SendVerificationCode mengirim OTP ke email/phone milik userID. Kode lama diganti,
+// dan kode baru baru bisa diminta lagi setelah OTPResendInterval.
+func (s *Service) SendVerificationCode(userID uint, channel string) error {
+	contact, ok := verificationContacts[channel]
+	if !ok {
+		return fmt.Errorf("unknown verification channel %q", channel)
+	}
+
+	ctx := context.Background()
+
+	profile, err := s.repos.Profiles.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUserNotFound
+	} else if err != nil {
+		return err
+	}
+	destination, verified := profile.Email, profile.EmailVerified
+	if contact == repository.ContactPhone {
+		destination, verified = profile.Phone, profile.PhoneVerified
+	}
+	if destination == nil || *destination == "" {
+		return ErrContactNotSet
+	}
+	if verified {
+		return ErrContactAlreadyVerified
+	}
+
+	code, err := randomOTP()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	err = s.repos.Verifications.Save(ctx, &repository.VerificationCode{
+		UserId:      userID,
+		Channel:     channel,
+		Destination: *destination,
+		CodeHash:    hashOTP(userID, *destination, code),
+		ExpiresAt:   now.Add(s.cfg.OTPTTL),
+		CreatedAt:   now,
+	}, now.Add(-s.cfg.OTPResendInterval))
+	if errors.Is(err, repository.ErrTooSoon) {
+		return ErrOTPTooSoon
+	} else if err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, notifier.Message{
+		Channel: channel,
+		To:      *destination,
+		Subject: "Kode verifikasi",
+		Body:    fmt.Sprintf("Kode verifikasi Anda: %s\nBerlaku %d menit.", code, int(s.cfg.OTPTTL.Minutes())),
+	})
+}
+
+// VerifyCode mencocokkan OTP. Setiap percobaan dihitung sebelum kode dicocokkan, setelah OTPMaxAttempts
+// kode harus diminta ulang. Kode hanya berlaku untuk kontak yang sama dengan saat dikirim.
+func (s *Service) VerifyCode(userID uint, channel, code string) error {
+	contact, ok := verificationContacts[channel]
+	if !ok {
+		return fmt.Errorf("unknown verification channel %q", channel)
+	}
+
+	ctx := context.Background()
+
+	stored, err := s.repos.Verifications.ClaimAttempt(ctx, userID, channel, s.cfg.OTPMaxAttempts)
+	if errors.Is(err, repository.ErrNotFound) {
+		return s.unclaimedCodeError(ctx, userID, channel)
+	} else if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOTP(userID, stored.Destination, code)), []byte(stored.CodeHash)) != 1 {
+		return ErrInvalidOTP
+	}
+
+	// Kontak yang diganti setelah kode dikirim tidak ikut terverifikasi
+	if err := s.repos.Verifications.Confirm(ctx, stored, contact); errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidOTP
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// unclaimedCodeError alasan ClaimAttempt ditolak: kode tidak ada, percobaan habis atau kedaluwarsa
+func (s *Service) unclaimedCodeError(ctx context.Context, userID uint, channel string) error {
+	stored, err := s.repos.Verifications.Find(ctx, userID, channel)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidOTP
+	} else if err != nil {
+		return err
+	}
+	if stored.Attempts >= s.cfg.OTPMaxAttempts {
+		return ErrOTPAttemptsExceeded
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return ErrOTPExpired
+	}
+	// Kode diganti di antara dua query, anggap percobaan ini gagal
+	return ErrInvalidOTP
+}
+
+// IsUserVerified true kalau minimal satu kontak user sudah diverifikasi
+func (s *Service) IsUserVerified(userID uint) (bool, error) {
+	profile, err := s.repos.Profiles.Get(context.Background(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return profile.EmailVerified || profile.PhoneVerified, nil
+}
+
+func randomOTP() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < otpDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %v", err)
+	}
+	return fmt.Sprintf("%0*d", otpDigits, n), nil
+}
+
+// hashOTP kode diikat ke user dan kontak tujuan supaya hash yang sama tidak bisa dipakai di tempat lain
+func hashOTP(userID uint, destination, code string) string {
+	return hashToken(fmt.Sprintf("%d:%s:%s", userID, destination, code))
+}
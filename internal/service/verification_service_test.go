@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"errors"
+	"sprint3/internal/notifier"
+	"sprint3/internal/service"
+	"sync"
+	"testing"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	svc, notify := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+
+	if err := svc.SendVerificationCode(user.Id, notifier.ChannelSMS); !errors.Is(err, service.ErrContactNotSet) {
+		t.Fatalf("sms without phone error = %v, want ErrContactNotSet", err)
+	}
+	if err := svc.SendVerificationCode(user.Id, notifier.ChannelEmail); err != nil {
+		t.Fatalf("SendVerificationCode: %v", err)
+	}
+	code := notify.secret(t, "Kode verifikasi Anda: ")
+	if err := svc.SendVerificationCode(user.Id, notifier.ChannelEmail); !errors.Is(err, service.ErrOTPTooSoon) {
+		t.Fatalf("resend error = %v, want ErrOTPTooSoon", err)
+	}
+
+	if err := svc.VerifyCode(user.Id, notifier.ChannelEmail, "000000x"); !errors.Is(err, service.ErrInvalidOTP) {
+		t.Fatalf("wrong code error = %v, want ErrInvalidOTP", err)
+	}
+	if verified, _ := svc.IsUserVerified(user.Id); verified {
+		t.Fatal("user verified by a wrong code")
+	}
+
+	if err := svc.VerifyCode(user.Id, notifier.ChannelEmail, code); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+	if verified, _ := svc.IsUserVerified(user.Id); !verified {
+		t.Fatal("user not verified after a correct code")
+	}
+	if err := svc.SendVerificationCode(user.Id, notifier.ChannelEmail); !errors.Is(err, service.ErrContactAlreadyVerified) {
+		t.Fatalf("send after verify error = %v, want ErrContactAlreadyVerified", err)
+	}
+}
+
+func TestVerifyCodeAttemptLimit(t *testing.T) {
+	svc, notify := newTestService(t)
+	user, err := svc.RegisterUserPhone("+628111", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserPhone: %v", err)
+	}
+	if err := svc.SendVerificationCode(user.Id, notifier.ChannelSMS); err != nil {
+		t.Fatalf("SendVerificationCode: %v", err)
+	}
+	code := notify.secret(t, "Kode verifikasi Anda: ")
+
+	// testConfig membatasi 3 percobaan, setelah itu kode yang benar pun ditolak
+	for i := 0; i < 3; i++ {
+		if err := svc.VerifyCode(user.Id, notifier.ChannelSMS, "wrong"); !errors.Is(err, service.ErrInvalidOTP) {
+			t.Fatalf("attempt %d error = %v, want ErrInvalidOTP", i+1, err)
+		}
+	}
+	if err := svc.VerifyCode(user.Id, notifier.ChannelSMS, code); !errors.Is(err, service.ErrOTPAttemptsExceeded) {
+		t.Fatalf("error after limit = %v, want ErrOTPAttemptsExceeded", err)
+	}
+}
+
+func TestVerifyCodeConcurrentGuesses(t *testing.T) {
+	svc, notify := newTestService(t)
+	user, err := svc.RegisterUserEmail("buyer@example.com", "password123")
+	if err != nil {
+		t.Fatalf("RegisterUserEmail: %v", err)
+	}
+	if err := svc.SendVerificationCode(user.Id, notifier.ChannelEmail); err != nil {
+		t.Fatalf("SendVerificationCode: %v", err)
+	}
+	code := notify.secret(t, "Kode verifikasi Anda: ")
+
+	// Tebakan yang dikirim bersamaan tidak boleh melewati batas 3 percobaan
+	const guesses = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, guesses)
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- svc.VerifyCode(user.Id, notifier.ChannelEmail, "wrong")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	invalid, exceeded := 0, 0
+	for err := range errs {
+		switch {
+		case errors.Is(err, service.ErrInvalidOTP):
+			invalid++
+		case errors.Is(err, service.ErrOTPAttemptsExceeded):
+			exceeded++
+		default:
+			t.Fatalf("VerifyCode error = %v", err)
+		}
+	}
+	if invalid != 3 || exceeded != guesses-3 {
+		t.Fatalf("invalid = %d, exceeded = %d, want 3 and %d", invalid, exceeded, guesses-3)
+	}
+	if err := svc.VerifyCode(user.Id, notifier.ChannelEmail, code); !errors.Is(err, service.ErrOTPAttemptsExceeded) {
+		t.Fatalf("correct code after limit error = %v, want ErrOTPAttemptsExceeded", err)
+	}
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+const (
+	jpegMarkerSOS   = 0xDA
+	jpegMarkerAPP1  = 0xE1 // EXIF dan XMP
+	jpegMarkerAPP13 = 0xED // IPTC / Photoshop
+	jpegMarkerCOM   = 0xFE
+
+	exifTagOrientation = 0x0112
+)
+
+// jpegSegments memanggil fn untuk setiap segment sebelum SOS.
+// fn menerima marker dan seluruh byte segment (termasuk 0xFF, marker dan panjang).
+// Nilai kembalian adalah offset awal SOS, atau -1 kalau struktur JPEG tidak valid.
+func jpegSegments(data []byte, fn func(marker byte, segment []byte)) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return -1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return -1
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Padding antar marker
+			pos++
+			continue
+		}
+		if marker == jpegMarkerSOS {
+			return pos
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return -1
+		}
+		fn(marker, data[pos:end])
+		pos = end
+	}
+	return -1
+}
+
+// jpegOrientation membaca tag Orientation dari EXIF, 1 kalau tidak ada atau tidak valid
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, segment []byte) {
+		if marker != jpegMarkerAPP1 || len(segment) < 4 {
+			return
+		}
+		payload := segment[4:]
+		if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return
+		}
+		if o := tiffOrientation(payload[6:]); o >= 1 && o <= 8 {
+			orientation = o
+		}
+	})
+	return orientation
+}
+
+// tiffOrientation mencari tag Orientation di IFD0 dari blok TIFF milik EXIF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:4]) != 0x002A {
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == exifTagOrientation {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+	return 0
+}
+
+// stripJPEGMetadata membuang segment EXIF/XMP (termasuk GPS), IPTC dan komentar tanpa re-encode.
+// Segment lain seperti ICC profile dan Adobe dipertahankan karena memengaruhi warna hasil decode.
+func stripJPEGMetadata(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	sos := jpegSegments(data, func(marker byte, segment []byte) {
+		if marker == jpegMarkerAPP1 || marker == jpegMarkerAPP13 || marker == jpegMarkerCOM {
+			return
+		}
+		out = append(out, segment...)
+	})
+	if sos < 0 {
+		return data
+	}
+	return append(out, data[sos:]...)
+}
+
+// pngMetadataChunks chunk PNG berisi metadata yang tidak dibutuhkan untuk menampilkan gambar
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"iTXt": true,
+	"zTXt": true,
+	"tIME": true,
+}
+
+// stripPNGMetadata membuang chunk EXIF dan teks dari PNG tanpa re-encode
+func stripPNGMetadata(data []byte) []byte {
+	const signatureLength = 8
+	if len(data) < signatureLength {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:signatureLength]...)
+	pos := signatureLength
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return data
+		}
+		if !pngMetadataChunks[string(data[pos+4:pos+8])] {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if pos != len(data) {
+		return data
+	}
+	return out
+}
+
+// applyOrientation memutar/membalik gambar sesuai nilai EXIF Orientation (1-8)
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // flip vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 searah jarum jam
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 berlawanan jarum jam
+				dx, dy = y, w-1-x
+			}
+			dst.SetNRGBA(dx, dy, src.NRGBAAt(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/nfnt/resize"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"sort"
+	"sprint3/pkg/config"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Batas dimensi sebelum gambar di-decode, mencegah decode bomb (file kecil dengan dimensi raksasa)
+	MaxImageDimension = 4096
+	MaxImagePixels    = 4096 * 4096
+
+	// Nama object selalu unik, jadi object boleh di-cache selamanya
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+	// PrivateCacheControl object private tidak boleh disimpan cache bersama atau CDN
+	PrivateCacheControl = "private, no-store"
+)
+
+var (
+	ErrUnsupportedImageType = errors.New("unsupported image type")
+	ErrImageTypeMismatch    = errors.New("file extension does not match image content")
+	ErrImageTooLarge        = errors.New("image dimensions too large")
+)
+
+// ImageType hasil deteksi tipe gambar dari isi file
+type ImageType struct {
+	ContentType string
+	// Format nama format dari package image, misal "jpeg" atau "png"
+	Format    string
+	Extension string
+	Width     int
+	Height    int
+}
+
+// ImageOptions pengaturan pipeline gambar, diisi dari config lewat ParseImageOptions
+type ImageOptions struct {
+	// RenditionSizes ukuran sisi terpanjang (px) untuk setiap rendition yang dibuat
+	RenditionSizes []int
+	// AllowGIF menerima upload GIF, rendition dibuat dari frame pertama
+	AllowGIF bool
+}
+
+// ParseImageOptions membaca ukuran rendition dari config, contoh "100,300,800"
+func ParseImageOptions(cfg *config.Config) (ImageOptions, error) {
+	opts := ImageOptions{AllowGIF: cfg.AllowGIFUpload}
+	for _, part := range strings.Split(cfg.ImageRenditionSizes, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		size, err := strconv.Atoi(part)
+		if err != nil || size <= 0 || size > MaxImageDimension {
+			return opts, fmt.Errorf("invalid rendition size %q", part)
+		}
+		opts.RenditionSizes = append(opts.RenditionSizes, size)
+	}
+	if len(opts.RenditionSizes) == 0 {
+		return opts, errors.New("at least one rendition size is required")
+	}
+	sort.Ints(opts.RenditionSizes)
+	return opts, nil
+}
+
+// allowedImageTypes content type yang diterima beserta ekstensi yang cocok
+var allowedImageTypes = map[string][]string{
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/png":  {".png"},
+	"image/gif":  {".gif"},
+}
+
+// DetectImageType membaca magic bytes dan header gambar tanpa men-decode seluruh pixel.
+// Ekstensi nama file harus cocok dengan isi file.
+func (o ImageOptions) DetectImageType(data []byte, fileExtension string) (*ImageType, error) {
+	contentType := http.DetectContentType(data)
+	extensions, ok := allowedImageTypes[contentType]
+	if !ok || (contentType == "image/gif" && !o.AllowGIF) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageType, contentType)
+	}
+
+	fileExtension = strings.ToLower(fileExtension)
+	matched := false
+	for _, ext := range extensions {
+		if ext == fileExtension {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("%w: %s is %s", ErrImageTypeMismatch, fileExtension, contentType)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImageType, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxImageDimension || cfg.Height > MaxImageDimension ||
+		cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	return &ImageType{
+		ContentType: contentType,
+		Format:      format,
+		Extension:   extensions[0],
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+// ProcessedImage hasil pipeline gambar yang siap diunggah
+type ProcessedImage struct {
+	// Original file asli tanpa metadata EXIF/GPS, sudah diputar sesuai orientasi
+	Original    []byte
+	ContentType string
+	Extension   string
+	Renditions  []ProcessedRendition
+}
+
+type ProcessedRendition struct {
+	Size        int
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// ProcessImage membersihkan metadata, memperbaiki orientasi EXIF dan membuat semua rendition.
+// Dimensi gambar harus sudah divalidasi lewat DetectImageType sebelum di-decode di sini.
+func (o ImageOptions) ProcessImage(data []byte, imgType *ImageType) (*ProcessedImage, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	processed := &ProcessedImage{
+		Original:    data,
+		ContentType: imgType.ContentType,
+		Extension:   imgType.Extension,
+	}
+
+	switch imgType.Format {
+	case "jpeg":
+		if orientation := jpegOrientation(data); orientation > 1 {
+			// Pixel harus diputar, jadi file asli di-encode ulang (metadata otomatis hilang)
+			img = applyOrientation(img, orientation)
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+				return nil, fmt.Errorf("failed to encode image: %v", err)
+			}
+			processed.Original = buf.Bytes()
+		} else {
+			processed.Original = stripJPEGMetadata(data)
+		}
+	case "png":
+		processed.Original = stripPNGMetadata(data)
+	case "gif":
+		// GIF tidak membawa EXIF, file asli (termasuk animasi) disimpan apa adanya
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageType, imgType.Format)
+	}
+
+	for _, size := range o.RenditionSizes {
+		rendition, err := createRendition(img, imgType, size)
+		if err != nil {
+			return nil, err
+		}
+		processed.Renditions = append(processed.Renditions, *rendition)
+	}
+	return processed, nil
+}
+
+// createRendition mengecilkan gambar sampai sisi terpanjang maksimal size, gambar kecil tidak diperbesar
+func createRendition(img image.Image, imgType *ImageType, size int) (*ProcessedRendition, error) {
+	thumb := resize.Thumbnail(uint(size), uint(size), img, resize.Lanczos3)
+
+	var buf bytes.Buffer
+	rendition := &ProcessedRendition{Size: size}
+	switch imgType.Format {
+	case "jpeg":
+		rendition.ContentType, rendition.Extension = "image/jpeg", ".jpg"
+		if err := jpeg.Encode(&buf, thumb, nil); err != nil {
+			return nil, fmt.Errorf("failed to encode %dpx rendition: %v", size, err)
+		}
+	default:
+		// PNG dan frame pertama GIF disimpan sebagai PNG
+		rendition.ContentType, rendition.Extension = "image/png", ".png"
+		if err := png.Encode(&buf, thumb); err != nil {
+			return nil, fmt.Errorf("failed to encode %dpx rendition: %v", size, err)
+		}
+	}
+	rendition.Data = buf.Bytes()
+	return rendition, nil
+}
@@ -0,0 +1,90 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"sprint3/internal/storage"
+	"sprint3/internal/testutil"
+	"testing"
+)
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeGIF(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	palette := color.Palette{color.Black, color.White}
+	if err := gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, width, height), palette), nil); err != nil {
+		t.Fatalf("encode gif: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectImageType(t *testing.T) {
+	pngData := testutil.EncodePNG(t, 40, 20)
+	jpegData := encodeJPEG(t, 30, 10)
+	gifData := encodeGIF(t, 8, 8)
+
+	tests := []struct {
+		name      string
+		opts      storage.ImageOptions
+		data      []byte
+		extension string
+		wantType  string
+		wantErr   error
+	}{
+		{name: "png", data: pngData, extension: ".png", wantType: "image/png"},
+		{name: "uppercase extension", data: pngData, extension: ".PNG", wantType: "image/png"},
+		{name: "jpeg as jpg", data: jpegData, extension: ".jpg", wantType: "image/jpeg"},
+		{name: "jpeg as jpeg", data: jpegData, extension: ".jpeg", wantType: "image/jpeg"},
+		{name: "png named jpg", data: pngData, extension: ".jpg", wantErr: storage.ErrImageTypeMismatch},
+		{name: "jpeg named png", data: jpegData, extension: ".png", wantErr: storage.ErrImageTypeMismatch},
+		{name: "missing extension", data: pngData, extension: "", wantErr: storage.ErrImageTypeMismatch},
+		{name: "gif disabled", data: gifData, extension: ".gif", wantErr: storage.ErrUnsupportedImageType},
+		{name: "gif enabled", opts: storage.ImageOptions{AllowGIF: true}, data: gifData, extension: ".gif", wantType: "image/gif"},
+		{name: "text", data: []byte("hello world"), extension: ".png", wantErr: storage.ErrUnsupportedImageType},
+		{name: "truncated png", data: pngData[:16], extension: ".png", wantErr: storage.ErrUnsupportedImageType},
+		{name: "too wide", data: testutil.EncodePNG(t, storage.MaxImageDimension+1, 1), extension: ".png", wantErr: storage.ErrImageTooLarge},
+		{name: "too tall", data: testutil.EncodePNG(t, 1, storage.MaxImageDimension+1), extension: ".png", wantErr: storage.ErrImageTooLarge},
+		{name: "max dimension", data: testutil.EncodePNG(t, storage.MaxImageDimension, 1), extension: ".png", wantType: "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imgType, err := tt.opts.DetectImageType(tt.data, tt.extension)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DetectImageType error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectImageType: %v", err)
+			}
+			if imgType.ContentType != tt.wantType {
+				t.Fatalf("content type = %s, want %s", imgType.ContentType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestDetectImageTypeDimensions(t *testing.T) {
+	imgType, err := storage.ImageOptions{}.DetectImageType(testutil.EncodePNG(t, 40, 20), ".png")
+	if err != nil {
+		t.Fatalf("DetectImageType: %v", err)
+	}
+	if imgType.Width != 40 || imgType.Height != 20 || imgType.Format != "png" || imgType.Extension != ".png" {
+		t.Fatalf("image type = %+v, want 40x20 png", imgType)
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStorage menyimpan object di memory proses, isinya hilang saat aplikasi berhenti.
+// Dipakai untuk menjalankan aplikasi atau test tanpa S3.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	baseURL string
+	signer  *URLSigner
+}
+
+type memoryObject struct {
+	data    []byte
+	opts    PutOptions
+	modTime time.Time
+}
+
+func NewMemoryStorage(baseURL string, signer *URLSigner) *MemoryStorage {
+	if baseURL == "" {
+		baseURL = LocalServePath
+	}
+	return &MemoryStorage{
+		objects: map[string]memoryObject{},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		signer:  signer,
+	}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryObject{data: data, opts: opts, modTime: time.Now()}
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(object.data)), nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *MemoryStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	return ObjectInfo{
+		Key:          key,
+		Size:         int64(len(object.data)),
+		ContentType:  object.opts.ContentType,
+		LastModified: object.modTime,
+	}, nil
+}
+
+func (s *MemoryStorage) PresignURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.signer.SignURL(s.URL(key), http.MethodGet, key, expires), nil
+}
+
+func (s *MemoryStorage) PresignPutURL(ctx context.Context, key, contentType string, expires time.Duration) (string, error) {
+	return s.signer.SignURL(s.URL(key), http.MethodPut, key, expires), nil
+}
+
+func (s *MemoryStorage) VerifySignedURL(method, key string, query url.Values) error {
+	return s.signer.Verify(method, key, query)
+}
+
+func (s *MemoryStorage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	// Salin dulu supaya fn boleh memanggil Delete tanpa deadlock
+	s.mu.RLock()
+	objects := make([]ObjectInfo, 0, len(s.objects))
+	for key, object := range s.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: int64(len(object.data)), LastModified: object.modTime})
+	}
+	s.mu.RUnlock()
+
+	for _, object := range objects {
+		if err := fn(object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *MemoryStorage) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// IncomingPrefix prefix key untuk upload langsung yang belum diproses.
+// Object yang tidak pernah diselesaikan akan dihapus oleh orphan sweeper.
+const IncomingPrefix = "incoming/"
+
+// PrivatePrefix prefix key untuk object private, hanya bisa dibaca lewat signed URL
+const PrivatePrefix = "private/"
+
+// PublicPrefix prefix key untuk object publik hasil upload.
+// Object di luar PublicPrefix, PrivatePrefix dan IncomingPrefix bukan milik aplikasi dan tidak disentuh sweeper.
+const PublicPrefix = "public/"
+
+// PresignOptions batas upload langsung lewat presigned URL, diisi dari config saat NewClient
+type PresignOptions struct {
+	MaxUploadSize int64
+	Expiry        time.Duration
+	// PrivateURLExpiry masa berlaku signed GET URL untuk file private
+	PrivateURLExpiry time.Duration
+}
+
+// PresignedUpload URL PUT yang diberikan ke client beserta key tujuannya
+type PresignedUpload struct {
+	Key         string
+	URL         string
+	ContentType string
+	ExpiresAt   time.Time
+}
+
+// PresignUpload membuat presigned PUT URL untuk gambar baru milik userID
+func (c *Client) PresignUpload(ctx context.Context, userID uint, contentType string) (*PresignedUpload, error) {
+	extensions, ok := allowedImageTypes[contentType]
+	if !ok || (contentType == "image/gif" && !c.Image.AllowGIF) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageType, contentType)
+	}
+
+	key := fmt.Sprintf("%s%d/%s%s", IncomingPrefix, userID, uuid.New().String(), extensions[0])
+	expiresAt := time.Now().Add(c.Presign.Expiry)
+	uploadURL, err := c.PresignPutURL(ctx, key, contentType, c.Presign.Expiry)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignedUpload{Key: key, URL: uploadURL, ContentType: contentType, ExpiresAt: expiresAt}, nil
+}
+
+// IsIncomingKeyOf memastikan key dibuat oleh PresignUpload untuk userID
+func IsIncomingKeyOf(key string, userID uint) bool {
+	prefix := fmt.Sprintf("%s%d/", IncomingPrefix, userID)
+	rest := strings.TrimPrefix(key, prefix)
+	return rest != key && rest != "" && !strings.Contains(rest, "/")
+}
+
+// IsIncomingKey key hasil PresignUpload yang belum diproses
+func IsIncomingKey(key string) bool {
+	return strings.HasPrefix(key, IncomingPrefix)
+}
+
+// IsPrivateKey key object private yang hanya boleh dibaca lewat signed URL
+func IsPrivateKey(key string) bool {
+	return strings.HasPrefix(key, PrivatePrefix)
+}
+
+// SignPrivateURLs mengganti URL permanen object dengan signed GET URL berumur pendek.
+// keys adalah semua object milik file, uri yang tidak cocok dengan key manapun dibiarkan.
+func (c *Client) SignPrivateURLs(ctx context.Context, keys []string, uris ...*string) error {
+	keyByURL := make(map[string]string, len(keys))
+	for _, key := range keys {
+		keyByURL[c.URL(key)] = key
+	}
+
+	for _, uri := range uris {
+		key, ok := keyByURL[*uri]
+		if !ok {
+			continue
+		}
+		signed, err := c.PresignURL(ctx, key, c.Presign.PrivateURLExpiry)
+		if err != nil {
+			return fmt.Errorf("failed to sign %s: %v", key, err)
+		}
+		*uri = signed
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package storage_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sprint3/internal/storage"
+	"sprint3/pkg/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedQuery menandatangani key dan mengembalikan query expires/signature-nya
+func signedQuery(t *testing.T, signer *storage.URLSigner, method, key string, expires time.Duration) url.Values {
+	t.Helper()
+	signed, err := url.Parse(signer.SignURL("http://files.test/"+key, method, key, expires))
+	if err != nil {
+		t.Fatalf("parse signed url: %v", err)
+	}
+	return signed.Query()
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	signer := storage.NewURLSigner("secret")
+	key := storage.PrivatePrefix + "a.png"
+	query := signedQuery(t, signer, http.MethodGet, key, time.Minute)
+
+	if err := signer.Verify(http.MethodGet, key, query); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	tampered := url.Values{"expires": {query.Get("expires")}, "signature": {strings.Repeat("0", len(query.Get("signature")))}}
+	extended := url.Values{"expires": {"99999999999"}, "signature": {query.Get("signature")}}
+
+	tests := []struct {
+		name   string
+		signer *storage.URLSigner
+		method string
+		key    string
+		query  url.Values
+	}{
+		{name: "other key", signer: signer, method: http.MethodGet, key: storage.PrivatePrefix + "b.png", query: query},
+		{name: "other method", signer: signer, method: http.MethodPut, key: key, query: query},
+		{name: "other secret", signer: storage.NewURLSigner("other"), method: http.MethodGet, key: key, query: query},
+		{name: "tampered signature", signer: signer, method: http.MethodGet, key: key, query: tampered},
+		{name: "extended expiry", signer: signer, method: http.MethodGet, key: key, query: extended},
+		{name: "no signature", signer: signer, method: http.MethodGet, key: key, query: url.Values{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.signer.Verify(tt.method, tt.key, tt.query); !errors.Is(err, storage.ErrInvalidSignature) {
+				t.Fatalf("Verify error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestURLSignerExpiry(t *testing.T) {
+	signer := storage.NewURLSigner("secret")
+	key := storage.PrivatePrefix + "a.png"
+
+	query := signedQuery(t, signer, http.MethodGet, key, -time.Minute)
+	if err := signer.Verify(http.MethodGet, key, query); !errors.Is(err, storage.ErrSignatureExpired) {
+		t.Fatalf("Verify expired url error = %v, want ErrSignatureExpired", err)
+	}
+}
+
+func TestURLSignerRandomSecret(t *testing.T) {
+	// Tanpa secret setiap signer punya secret acak sendiri, URL signer lain tidak berlaku
+	first, second := storage.NewURLSigner(""), storage.NewURLSigner("")
+	key := storage.PrivatePrefix + "a.png"
+
+	query := signedQuery(t, first, http.MethodGet, key, time.Minute)
+	if err := first.Verify(http.MethodGet, key, query); err != nil {
+		t.Fatalf("Verify with same signer: %v", err)
+	}
+	if err := second.Verify(http.MethodGet, key, query); !errors.Is(err, storage.ErrInvalidSignature) {
+		t.Fatalf("Verify with other signer error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestNewRequiresSigningSecretInProduction(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		driver  string
+		secret  string
+		wantErr bool
+	}{
+		{name: "production without secret", env: "production", driver: storage.DriverMemory, wantErr: true},
+		{name: "production local without secret", env: "production", driver: storage.DriverLocal, wantErr: true},
+		{name: "production with secret", env: "production", driver: storage.DriverMemory, secret: "secret"},
+		{name: "development without secret", env: "development", driver: storage.DriverMemory},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				AppEnv:               tt.env,
+				StorageDriver:        tt.driver,
+				StorageSigningSecret: tt.secret,
+				LocalStorageDir:      t.TempDir(),
+				StorageBaseURL:       "http://files.test" + storage.LocalServePath,
+			}
+			_, err := storage.New(cfg)
+			if tt.wantErr && err == nil {
+				t.Fatal("New succeeded, want error for missing STORAGE_SIGNING_SECRET")
+			} else if !tt.wantErr && err != nil {
+				t.Fatalf("New: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sprint3/pkg/config"
+	"time"
+)
+
+const (
+	DriverS3     = "s3"
+	DriverLocal  = "local"
+	DriverMemory = "memory"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// PutOptions metadata yang disimpan bersama object
+type PutOptions struct {
+	ContentType  string
+	CacheControl string
+	// Private object tidak bisa dibaca publik, hanya lewat PresignURL
+	Private bool
+}
+
+// ObjectInfo ringkasan object hasil List
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage abstraksi object storage yang dipakai untuk menyimpan file upload
+type Storage interface {
+	// Put menyimpan body dengan key tertentu, key yang sudah ada akan ditimpa
+	Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error
+	// Get membuka object, pemanggil wajib menutup reader-nya
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// Stat mengambil metadata object tanpa membaca isinya
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// PresignURL menghasilkan URL GET yang hanya berlaku selama expires
+	PresignURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	// PresignPutURL menghasilkan URL PUT untuk upload langsung dari client.
+	// Client wajib mengirim header Content-Type yang sama dengan contentType.
+	PresignPutURL(ctx context.Context, key, contentType string, expires time.Duration) (string, error)
+	// URL menghasilkan URL permanen object
+	URL(key string) string
+	// List memanggil fn untuk setiap object yang key-nya diawali prefix, berhenti kalau fn mengembalikan error
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	// Ping memastikan backend bisa dipakai, untuk readiness check
+	Ping(ctx context.Context) error
+}
+
+// SignedURLVerifier diimplementasikan backend yang object-nya dilayani langsung oleh aplikasi
+// (local dan memory), untuk memeriksa URL hasil PresignURL/PresignPutURL.
+type SignedURLVerifier interface {
+	VerifySignedURL(method, key string, query url.Values) error
+}
+
+// New membuat backend storage sesuai cfg.StorageDriver
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case DriverS3:
+		return NewS3Storage(cfg)
+	case DriverLocal:
+		signer, err := signerFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalStorage(cfg.LocalStorageDir, cfg.StorageBaseURL, signer)
+	case DriverMemory:
+		signer, err := signerFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewMemoryStorage(cfg.StorageBaseURL, signer), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// signerFromConfig signer driver local/memory. Di production aplikasi wajib gagal start kalau
+// STORAGE_SIGNING_SECRET kosong, sama seperti JWT key, karena secret acak berbeda di setiap instance
+// dan hilang saat restart.
+func signerFromConfig(cfg *config.Config) (*URLSigner, error) {
+	if cfg.StorageSigningSecret == "" {
+		if cfg.IsProduction() {
+			return nil, errors.New("STORAGE_SIGNING_SECRET is required in production")
+		}
+		log.Println("⚠️  WARNING: STORAGE_SIGNING_SECRET is not set, using a random secret (signed URLs will not survive a restart)")
+	}
+	return NewURLSigner(cfg.StorageSigningSecret), nil
+}
+
+// Client backend storage beserta opsi pipeline gambar dan presign, satu instance per aplikasi
+type Client struct {
+	Storage
+	Image   ImageOptions
+	Presign PresignOptions
+}
+
+// NewClient membuat Client dari cfg: backend sesuai cfg.StorageDriver, ukuran rendition dan batas presign
+func NewClient(cfg *config.Config) (*Client, error) {
+	backend, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	imageOptions, err := ParseImageOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image options: %v", err)
+	}
+	return &Client{
+		Storage: backend,
+		Image:   imageOptions,
+		Presign: PresignOptions{
+			MaxUploadSize:    cfg.PresignMaxUploadSize,
+			Expiry:           cfg.PresignExpiry,
+			PrivateURLExpiry: cfg.PrivateURLExpiry,
+		},
+	}, nil
+}
+
+// Close menutup koneksi backend yang punya koneksi sendiri (misal S3), backend lain tidak perlu ditutup
+func (c *Client) Close() error {
+	if closer, ok := c.Storage.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
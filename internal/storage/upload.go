@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"sprint3/internal/model"
+	"strings"
+	"time"
+)
+
+// GenerateUniqueFileName menghasilkan nama file unik menggunakan timestamp dan UUID
+func GenerateUniqueFileName(ext string) string {
+	// Menggunakan timestamp dan UUID untuk memastikan nama file unik
+	return fmt.Sprintf("%d_%s.%s", time.Now().Unix(), uuid.New().String(), ext)
+}
+
+// UploadedImage URL file asli dan semua rendition yang sudah diunggah
+type UploadedImage struct {
+	URI        string
+	Renditions []model.FileRendition
+	// Keys semua object yang dibuat, dipakai untuk menghapus kalau langkah berikutnya gagal
+	Keys []string
+}
+
+// ThumbnailURI rendition terkecil, dipakai sebagai fileThumbnailUri
+func (u *UploadedImage) ThumbnailURI() string {
+	if len(u.Renditions) == 0 {
+		return u.URI
+	}
+	smallest := u.Renditions[0]
+	for _, r := range u.Renditions[1:] {
+		if r.Size < smallest.Size {
+			smallest = r
+		}
+	}
+	return smallest.URI
+}
+
+// UploadImage mengunggah file asli dan semua rendition hasil ProcessImage ke storage.
+// Object publik disimpan di bawah PublicPrefix, object private di bawah PrivatePrefix tanpa akses publik.
+// Kalau salah satu gagal, object yang sudah terunggah dihapus lagi.
+func (c *Client) UploadImage(processed *ProcessedImage, private bool) (*UploadedImage, error) {
+	key, err := c.putObject(processed.Original, processed.Extension, processed.ContentType, private)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := &UploadedImage{URI: c.URL(key), Keys: []string{key}}
+	for _, rendition := range processed.Renditions {
+		key, err := c.putObject(rendition.Data, rendition.Extension, rendition.ContentType, private)
+		if err != nil {
+			c.DeleteObjects(uploaded.Keys)
+			return nil, fmt.Errorf("failed to upload %dpx rendition: %v", rendition.Size, err)
+		}
+		uploaded.Keys = append(uploaded.Keys, key)
+		uploaded.Renditions = append(uploaded.Renditions, model.FileRendition{Size: rendition.Size, URI: c.URL(key)})
+	}
+	return uploaded, nil
+}
+
+// DeleteObjects menghapus object hasil upload yang tidak jadi dipakai.
+// Kegagalan hanya di-log, object yang tertinggal akan dibersihkan oleh orphan sweeper.
+func (c *Client) DeleteObjects(keys []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := c.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete object %s: %v", key, err)
+		} else {
+			log.Printf("Deleted object %s", key)
+		}
+	}
+}
+
+// putObject mengunggah data dengan nama unik dan mengembalikan key-nya
+func (c *Client) putObject(data []byte, ext, contentType string, private bool) (string, error) {
+	// Menghasilkan nama file unik dari ekstensi file
+	prefix := PublicPrefix
+	if private {
+		prefix = PrivatePrefix
+	}
+	uniqueFileName := prefix + GenerateUniqueFileName(strings.TrimPrefix(ext, "."))
+
+	// Mengunggah file ke storage
+	log.Printf("Uploading file %v (%d bytes) to storage...", uniqueFileName, len(data))
+	opts := PutOptions{ContentType: contentType, CacheControl: ImmutableCacheControl, Private: private}
+	if private {
+		opts.CacheControl = PrivateCacheControl
+	}
+	if err := c.Put(context.Background(), uniqueFileName, bytes.NewReader(data), opts); err != nil {
+		log.Printf("Error uploading %v to storage: %v", uniqueFileName, err)
+		return "", err
+	}
+
+	log.Printf("File %v uploaded to storage successfully.", uniqueFileName)
+	return uniqueFileName, nil
+}
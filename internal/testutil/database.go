@@ -0,0 +1,60 @@
+package testutil
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"os"
+	"sprint3/pkg/database"
+	"testing"
+)
+
+// databaseLockID advisory lock yang dipegang selama satu test, berbeda dari lock migrasi dan sweeper
+const databaseLockID int64 = 7_302_118_542
+
+// Database pool ke TEST_DATABASE_URL yang sudah dimigrasi dan dikosongkan, test di-skip kalau variabelnya kosong.
+// Package yang berbeda dites paralel dengan database yang sama, jadi test yang memakai database
+// dijalankan bergantian lewat advisory lock.
+func Database(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	lock, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire lock connection: %v", err)
+	}
+	if _, err := lock.Exec(ctx, `SELECT pg_advisory_lock($1)`, databaseLockID); err != nil {
+		lock.Release()
+		t.Fatalf("lock test database: %v", err)
+	}
+	t.Cleanup(func() {
+		lock.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, databaseLockID)
+		lock.Release()
+	})
+
+	if _, err := database.MigrateUp(ctx, pool); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	_, err = pool.Exec(ctx, `DO $$
+        DECLARE tables text;
+        BEGIN
+            SELECT string_agg(format('%I.%I', schemaname, tablename), ', ') INTO tables
+            FROM pg_tables WHERE schemaname = 'public' AND tablename <> 'schema_migrations';
+            IF tables IS NOT NULL THEN
+                EXECUTE 'TRUNCATE ' || tables || ' RESTART IDENTITY CASCADE';
+            END IF;
+        END $$`)
+	if err != nil {
+		t.Fatalf("truncate test database: %v", err)
+	}
+	return pool
+}
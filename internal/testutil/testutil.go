@@ -0,0 +1,59 @@
+// Package testutil fixture bersama untuk test di package lain: config, storage di memori dan gambar contoh.
+// Hanya boleh diimpor dari file _test.go.
+package testutil
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"sprint3/internal/storage"
+	"sprint3/pkg/config"
+	"testing"
+	"time"
+)
+
+// Config config minimal untuk test, tanpa membaca .env. AppEnv "test" memakai key JWT sementara.
+func Config() *config.Config {
+	return &config.Config{
+		AppEnv:                       "test",
+		JWTIssuer:                    "sprint3",
+		JWTAudience:                  "sprint3-api",
+		AccessTokenTTL:               15 * time.Minute,
+		RefreshTokenTTL:              time.Hour,
+		ReadinessTimeout:             time.Second,
+		StorageBaseURL:               "http://files.test" + storage.LocalServePath,
+		StorageSigningSecret:         "secret",
+		PrivateURLExpiry:             time.Minute,
+		PasswordResetTTL:             time.Hour,
+		PasswordResetResendInterval:  time.Minute,
+		PasswordResetRequireVerified: true,
+		LoginMaxFailures:             3,
+		LoginIPMaxFailures:           100,
+		LoginFailureWindow:           15 * time.Minute,
+		LoginLockoutBase:             time.Minute,
+		LoginLockoutMax:              time.Hour,
+		OTPTTL:                       10 * time.Minute,
+		OTPMaxAttempts:               3,
+		OTPResendInterval:            time.Minute,
+		PurchaseReservationTTL:       30 * time.Minute,
+	}
+}
+
+// MemoryStorage storage di memori dengan signer dari cfg dan satu ukuran rendition kecil
+func MemoryStorage(cfg *config.Config) *storage.Client {
+	return &storage.Client{
+		Storage: storage.NewMemoryStorage(cfg.StorageBaseURL, storage.NewURLSigner(cfg.StorageSigningSecret)),
+		Image:   storage.ImageOptions{RenditionSizes: []int{16}},
+		Presign: storage.PresignOptions{MaxUploadSize: 1024 * 1024, Expiry: time.Minute, PrivateURLExpiry: cfg.PrivateURLExpiry},
+	}
+}
+
+// EncodePNG gambar PNG kosong berukuran width x height
+func EncodePNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -13,10 +15,105 @@ type Config struct {
 	DBPassword         string
 	DBName             string
 	DBSSLMode          string
-	JWTSecret          string
 	AWSAccessKeyID     string
 	AWSSecretAccessKey string
 	AWSRegion          string
+
+	// DBAutoMigrate menjalankan migration yang belum diterapkan saat aplikasi start
+	DBAutoMigrate bool
+
+	// Port HTTP server, timeout di bawah ini diteruskan ke http.Server
+	Port             string
+	HTTPReadTimeout  time.Duration
+	HTTPWriteTimeout time.Duration
+	HTTPIdleTimeout  time.Duration
+	// ShutdownTimeout batas waktu menunggu request dan background job selesai setelah SIGINT/SIGTERM
+	ShutdownTimeout time.Duration
+	// StartupRetryTimeout berapa lama koneksi database dicoba ulang saat start sebelum aplikasi menyerah
+	StartupRetryTimeout time.Duration
+	// ReadinessTimeout batas waktu ping setiap dependensi di /readyz
+	ReadinessTimeout time.Duration
+
+	// AppEnv "production" mewajibkan konfigurasi yang aman, misal JWT key
+	AppEnv string
+	// JWTKeyDir direktori berisi private key <kid>.pem (RSA atau Ed25519), JWTActiveKeyID kid untuk menandatangani
+	JWTKeyDir      string
+	JWTActiveKeyID string
+	// JWTIssuer dan JWTAudience klaim iss/aud yang ditulis dan diwajibkan di access token
+	JWTIssuer   string
+	JWTAudience string
+
+	// AccessTokenTTL umur JWT access token, RefreshTokenTTL umur refresh token sebelum harus login ulang
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	StorageDriver    string
+	S3Bucket         string
+	S3Endpoint       string
+	S3ForcePathStyle bool
+	S3PublicURL      string
+	LocalStorageDir  string
+	StorageBaseURL   string
+	// StorageSigningSecret secret HMAC untuk URL bertanda tangan driver local/memory
+	StorageSigningSecret string
+
+	// ImageRenditionSizes daftar ukuran thumbnail dalam px, dipisah koma (misal "100,300,800")
+	ImageRenditionSizes string
+	AllowGIFUpload      bool
+
+	// PresignMaxUploadSize batas ukuran (byte) upload langsung lewat presigned URL
+	PresignMaxUploadSize int64
+	PresignExpiry        time.Duration
+	// PrivateURLExpiry masa berlaku signed URL untuk membaca file private
+	PrivateURLExpiry time.Duration
+
+	// NotifierDriver log, file atau remote (email lewat SMTP, SMS lewat webhook)
+	NotifierDriver   string
+	NotifierFilePath string
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPFrom         string
+	SMSWebhookURL    string
+
+	// PasswordResetTTL masa berlaku token reset password
+	PasswordResetTTL time.Duration
+	// PasswordResetResendInterval jeda minimal antar token reset untuk akun yang sama
+	PasswordResetResendInterval time.Duration
+	// PasswordResetRequireVerified token reset hanya dikirim ke kontak yang sudah diverifikasi lewat OTP
+	PasswordResetRequireVerified bool
+
+	// Proteksi brute-force login: lockout setelah LoginMaxFailures gagal per akun
+	// (LoginIPMaxFailures per IP) dalam LoginFailureWindow, durasinya naik 2x tiap gagal lagi
+	LoginMaxFailures   int64
+	LoginIPMaxFailures int64
+	LoginFailureWindow time.Duration
+	LoginLockoutBase   time.Duration
+	LoginLockoutMax    time.Duration
+	// LoginUniformErrors akun tidak ditemukan dan password salah sama-sama dibalas 401 "Invalid credentials",
+	// default aktif di production
+	LoginUniformErrors bool
+	// LoginAttemptPruneInterval interval penghapusan hitungan login gagal yang sudah di luar window, 0 untuk mematikan
+	LoginAttemptPruneInterval time.Duration
+
+	// OTP verifikasi email/phone
+	OTPTTL            time.Duration
+	OTPMaxAttempts    int64
+	OTPResendInterval time.Duration
+	// RequireVerifiedSeller/Buyer membatasi jual (product) dan checkout (purchase) untuk user terverifikasi
+	RequireVerifiedSeller bool
+	RequireVerifiedBuyer  bool
+
+	// OrphanSweepInterval 0 berarti sweeper tidak dijalankan. Advisory lock memastikan hanya satu instance
+	// yang menyapu dalam satu waktu, jadi aman diaktifkan di semua instance.
+	OrphanSweepInterval time.Duration
+	OrphanGracePeriod   time.Duration
+
+	// PurchaseReservationTTL lama stok ditahan untuk order pending sebelum dilepas lagi
+	PurchaseReservationTTL time.Duration
+	// PurchaseExpiryInterval 0 berarti order kedaluwarsa hanya dilepas saat dibayar atau dibatalkan
+	PurchaseExpiryInterval time.Duration
 }
 
 func LoadEnv() *Config {
@@ -25,6 +122,8 @@ func LoadEnv() *Config {
 		log.Fatalf("Error loading .env file")
 	}
 
+	appEnv := getEnvDefault("APP_ENV", "development")
+
 	return &Config{
 		DBHost:             os.Getenv("DB_HOST"),
 		DBPort:             os.Getenv("DB_PORT"),
@@ -32,9 +131,117 @@ func LoadEnv() *Config {
 		DBPassword:         os.Getenv("DB_PASSWORD"),
 		DBName:             os.Getenv("DB_NAME"),
 		DBSSLMode:          os.Getenv("DB_SSL_MODE"),
-		JWTSecret:          os.Getenv("JWT_SECRET"),
 		AWSAccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
 		AWSSecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
 		AWSRegion:          os.Getenv("AWS_REGION"),
+
+		DBAutoMigrate: getEnvDefault("DB_AUTO_MIGRATE", "true") == "true",
+
+		Port:             getEnvDefault("PORT", "8081"),
+		HTTPReadTimeout:  getEnvDuration("HTTP_READ_TIMEOUT", 30*time.Second),
+		HTTPWriteTimeout: getEnvDuration("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		HTTPIdleTimeout:  getEnvDuration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout:  getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		StartupRetryTimeout: getEnvDuration("STARTUP_RETRY_TIMEOUT", time.Minute),
+		ReadinessTimeout:    getEnvDuration("READINESS_TIMEOUT", 2*time.Second),
+
+		AppEnv:         appEnv,
+		JWTKeyDir:      os.Getenv("JWT_KEY_DIR"),
+		JWTActiveKeyID: os.Getenv("JWT_ACTIVE_KID"),
+		JWTIssuer:      getEnvDefault("JWT_ISSUER", "sprint3"),
+		JWTAudience:    getEnvDefault("JWT_AUDIENCE", "sprint3-api"),
+
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		StorageDriver:    getEnvDefault("STORAGE_DRIVER", "s3"),
+		S3Bucket:         os.Getenv("S3_BUCKET"),
+		S3Endpoint:       os.Getenv("S3_ENDPOINT"),
+		S3ForcePathStyle: os.Getenv("S3_FORCE_PATH_STYLE") == "true",
+		S3PublicURL:      os.Getenv("S3_PUBLIC_URL"),
+		LocalStorageDir:  getEnvDefault("LOCAL_STORAGE_DIR", "uploads"),
+		StorageBaseURL:   os.Getenv("STORAGE_BASE_URL"),
+
+		StorageSigningSecret: os.Getenv("STORAGE_SIGNING_SECRET"),
+
+		ImageRenditionSizes: getEnvDefault("IMAGE_RENDITION_SIZES", "100,300,800"),
+		AllowGIFUpload:      os.Getenv("ALLOW_GIF_UPLOAD") == "true",
+
+		PresignMaxUploadSize: getEnvInt64("PRESIGN_MAX_UPLOAD_SIZE", 5*1024*1024),
+		PresignExpiry:        getEnvDuration("PRESIGN_EXPIRY", 15*time.Minute),
+		PrivateURLExpiry:     getEnvDuration("PRIVATE_URL_EXPIRY", 15*time.Minute),
+
+		NotifierDriver:   getEnvDefault("NOTIFIER_DRIVER", "log"),
+		NotifierFilePath: getEnvDefault("NOTIFIER_FILE_PATH", "notifications.log"),
+		SMTPHost:         os.Getenv("SMTP_HOST"),
+		SMTPPort:         getEnvDefault("SMTP_PORT", "587"),
+		SMTPUsername:     os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:     os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:         os.Getenv("SMTP_FROM"),
+		SMSWebhookURL:    os.Getenv("SMS_WEBHOOK_URL"),
+
+		PasswordResetTTL:             getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		PasswordResetResendInterval:  getEnvDuration("PASSWORD_RESET_RESEND_INTERVAL", time.Minute),
+		PasswordResetRequireVerified: getEnvDefault("PASSWORD_RESET_REQUIRE_VERIFIED", "true") == "true",
+
+		LoginMaxFailures:   getEnvInt64("LOGIN_MAX_FAILURES", 5),
+		LoginIPMaxFailures: getEnvInt64("LOGIN_IP_MAX_FAILURES", 20),
+		LoginFailureWindow: getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutBase:   getEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:    getEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		LoginUniformErrors: getEnvDefault("LOGIN_UNIFORM_ERRORS", strconv.FormatBool(appEnv == "production")) == "true",
+
+		LoginAttemptPruneInterval: getEnvDuration("LOGIN_ATTEMPT_PRUNE_INTERVAL", time.Hour),
+
+		OTPTTL:                getEnvDuration("OTP_TTL", 10*time.Minute),
+		OTPMaxAttempts:        getEnvInt64("OTP_MAX_ATTEMPTS", 5),
+		OTPResendInterval:     getEnvDuration("OTP_RESEND_INTERVAL", time.Minute),
+		RequireVerifiedSeller: os.Getenv("REQUIRE_VERIFIED_SELLER") == "true",
+		RequireVerifiedBuyer:  os.Getenv("REQUIRE_VERIFIED_BUYER") == "true",
+
+		OrphanSweepInterval: getEnvDuration("ORPHAN_SWEEP_INTERVAL", time.Hour),
+		OrphanGracePeriod:   getEnvDuration("ORPHAN_GRACE_PERIOD", 24*time.Hour),
+
+		PurchaseReservationTTL: getEnvDuration("PURCHASE_RESERVATION_TTL", 30*time.Minute),
+		PurchaseExpiryInterval: getEnvDuration("PURCHASE_EXPIRY_INTERVAL", time.Minute),
+	}
+}
+
+// IsProduction true kalau APP_ENV=production
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == "production"
+}
+
+func getEnvDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s (%q), using default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt64(key string, fallback int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Printf("Invalid number for %s (%q), using default %d", key, value, fallback)
+		return fallback
 	}
+	return n
 }
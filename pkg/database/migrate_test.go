@@ -0,0 +1,76 @@
+package database_test
+
+import (
+	"context"
+	"sprint3/internal/testutil"
+	"sprint3/pkg/database"
+	"testing"
+)
+
+func TestMigrateUpAdoptsHandMadeTables(t *testing.T) {
+	db := testutil.Database(t)
+	ctx := context.Background()
+
+	// Kembalikan schema lengkap supaya test lain tidak memakai tabel buatan test ini
+	t.Cleanup(func() {
+		if _, err := database.MigrateDown(ctx, db, 1000); err != nil {
+			t.Errorf("MigrateDown: %v", err)
+		}
+		if _, err := database.MigrateUp(ctx, db); err != nil {
+			t.Errorf("MigrateUp: %v", err)
+		}
+	})
+
+	if _, err := database.MigrateDown(ctx, db, 1000); err != nil {
+		t.Fatalf("MigrateDown: %v", err)
+	}
+	// Schema yang dulu dibuat manual, sebelum ada migration
+	_, err := db.Exec(ctx, `
+        CREATE TABLE public.user (
+            "userId" serial PRIMARY KEY, email text, phone text, password text NOT NULL, "createdAt" timestamp
+        );
+        CREATE TABLE file ("fileId" serial PRIMARY KEY, "fileUri" text, "fileThumbnailUri" text);
+        CREATE TABLE "userProfile" ("userId" integer PRIMARY KEY, email text, phone text, "fileId" integer);
+        INSERT INTO public.user (email, password, "createdAt") VALUES ('old@example.com', 'hash', now());
+        INSERT INTO file ("fileUri", "fileThumbnailUri") VALUES ('http://old/a.png', 'http://old/a.png');
+        INSERT INTO "userProfile" ("userId", email) VALUES (1, 'old@example.com');`)
+	if err != nil {
+		t.Fatalf("create hand-made tables: %v", err)
+	}
+
+	if _, err := database.MigrateUp(ctx, db); err != nil {
+		t.Fatalf("MigrateUp on hand-made tables: %v", err)
+	}
+
+	var roles []string
+	var suspended bool
+	err = db.QueryRow(ctx, `SELECT roles, "suspendedAt" IS NOT NULL FROM public.user WHERE email = 'old@example.com'`).
+		Scan(&roles, &suspended)
+	if err != nil {
+		t.Fatalf("read adopted user: %v", err)
+	}
+	if len(roles) != 2 || suspended {
+		t.Fatalf("adopted user roles = %v, suspended = %v, want default roles and not suspended", roles, suspended)
+	}
+
+	var ownerID int
+	var visibility string
+	if err := db.QueryRow(ctx, `SELECT "userId", visibility FROM file`).Scan(&ownerID, &visibility); err != nil {
+		t.Fatalf("read adopted file: %v", err)
+	}
+	if ownerID != 0 || visibility != "public" {
+		t.Fatalf("adopted file owner = %d, visibility = %s, want 0 and public", ownerID, visibility)
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO file ("fileUri", "fileThumbnailUri") VALUES ('u', 't')`); err == nil {
+		t.Fatal("new file without owner accepted, want the temporary userId default dropped")
+	}
+
+	// Email unik tetap dijaga di tabel lama
+	_, err = db.Exec(ctx, `INSERT INTO public.user (email, password) VALUES ('old@example.com', 'hash')`)
+	if err == nil {
+		t.Fatal("duplicate email accepted on adopted table")
+	}
+	if _, err := db.Exec(ctx, `UPDATE "userProfile" SET "bankAccountName" = 'BCA' WHERE "userId" = 1`); err != nil {
+		t.Fatalf("update added profile column: %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "userProfile";
+DROP TABLE IF EXISTS file;
+DROP TABLE IF EXISTS public.user;
@@ -0,0 +1,44 @@
+-- Database lama yang tabelnya dibuat manual (public.user, file, "userProfile") diadopsi: tabel yang sudah ada
+-- tidak dibuat ulang, kolom dan index yang belum ada ditambahkan. Tipe kolom yang sudah ada tidak diubah.
+CREATE TABLE IF NOT EXISTS public.user (
+    "userId" serial PRIMARY KEY
+);
+ALTER TABLE public.user
+    ADD COLUMN IF NOT EXISTS email             text,
+    ADD COLUMN IF NOT EXISTS phone             text,
+    ADD COLUMN IF NOT EXISTS password          text        NOT NULL,
+    ADD COLUMN IF NOT EXISTS roles             text[]      NOT NULL DEFAULT '{buyer,seller}',
+    ADD COLUMN IF NOT EXISTS "emailVerifiedAt" timestamptz,
+    ADD COLUMN IF NOT EXISTS "phoneVerifiedAt" timestamptz,
+    ADD COLUMN IF NOT EXISTS "suspendedAt"     timestamptz,
+    ADD COLUMN IF NOT EXISTS "createdAt"       timestamptz NOT NULL DEFAULT now();
+CREATE UNIQUE INDEX IF NOT EXISTS user_email_key ON public.user (email);
+CREATE UNIQUE INDEX IF NOT EXISTS user_phone_key ON public.user (phone);
+
+CREATE TABLE IF NOT EXISTS file (
+    "fileId" serial PRIMARY KEY
+);
+-- Tanpa foreign key, file tetap ada sebagai bukti purchase walaupun akunnya dihapus.
+-- File lama belum punya pemilik, "userId" 0 membuatnya tidak bisa dipakai akun mana pun.
+ALTER TABLE file
+    ADD COLUMN IF NOT EXISTS "userId"           integer     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "fileUri"          text        NOT NULL,
+    ADD COLUMN IF NOT EXISTS "fileThumbnailUri" text        NOT NULL,
+    ADD COLUMN IF NOT EXISTS renditions         jsonb       NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS "objectKeys"       text[]      NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS visibility         text        NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'private')),
+    ADD COLUMN IF NOT EXISTS "createdAt"        timestamptz NOT NULL DEFAULT now();
+ALTER TABLE file ALTER COLUMN "userId" DROP DEFAULT;
+CREATE INDEX IF NOT EXISTS file_user_id_idx ON file ("userId", "fileId");
+
+CREATE TABLE IF NOT EXISTS "userProfile" (
+    "userId" integer PRIMARY KEY REFERENCES public.user ("userId") ON DELETE CASCADE
+);
+ALTER TABLE "userProfile"
+    ADD COLUMN IF NOT EXISTS email               text,
+    ADD COLUMN IF NOT EXISTS phone               text,
+    ADD COLUMN IF NOT EXISTS "fileId"            integer REFERENCES file ("fileId"),
+    ADD COLUMN IF NOT EXISTS "bankAccountName"   text,
+    ADD COLUMN IF NOT EXISTS "bankAccountHolder" text,
+    ADD COLUMN IF NOT EXISTS "bankAccountNumber" text;
+CREATE INDEX IF NOT EXISTS user_profile_file_id_idx ON "userProfile" ("fileId");
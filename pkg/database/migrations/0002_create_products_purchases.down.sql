@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "purchasePayment";
+DROP TABLE IF EXISTS "purchaseItem";
+DROP TABLE IF EXISTS purchase;
+DROP TABLE IF EXISTS product;
@@ -0,0 +1,56 @@
+CREATE TABLE product (
+    "productId" serial PRIMARY KEY,
+    "userId"    integer     NOT NULL REFERENCES public.user ("userId"),
+    name        text        NOT NULL,
+    category    text        NOT NULL,
+    qty         integer     NOT NULL CHECK (qty >= 0),
+    price       integer     NOT NULL,
+    sku         text        NOT NULL,
+    "fileId"    integer     NOT NULL REFERENCES file ("fileId"),
+    sold        integer     NOT NULL DEFAULT 0,
+    "createdAt" timestamptz NOT NULL DEFAULT now(),
+    "updatedAt" timestamptz NOT NULL DEFAULT now(),
+    UNIQUE ("userId", sku)
+);
+CREATE INDEX product_file_id_idx ON product ("fileId");
+CREATE INDEX product_category_idx ON product (category);
+
+CREATE TABLE purchase (
+    "purchaseId"          serial PRIMARY KEY,
+    "userId"              integer     NOT NULL,
+    "senderName"          text        NOT NULL,
+    "senderContactType"   text        NOT NULL,
+    "senderContactDetail" text        NOT NULL,
+    "totalPrice"          integer     NOT NULL,
+    status                text        NOT NULL,
+    "createdAt"           timestamptz NOT NULL DEFAULT now(),
+    "paidAt"              timestamptz
+);
+
+-- Item dan pembayaran adalah snapshot saat checkout, jadi "productId"/"fileId" sengaja tanpa foreign key
+-- supaya product atau file yang di-take down admin tidak menghapus riwayat purchase
+CREATE TABLE "purchaseItem" (
+    "purchaseId" integer NOT NULL REFERENCES purchase ("purchaseId") ON DELETE CASCADE,
+    "productId"  integer NOT NULL,
+    "sellerId"   integer NOT NULL,
+    name         text    NOT NULL,
+    category     text    NOT NULL,
+    sku          text    NOT NULL,
+    price        integer NOT NULL,
+    qty          integer NOT NULL,
+    "fileId"     integer NOT NULL,
+    PRIMARY KEY ("purchaseId", "productId")
+);
+CREATE INDEX purchase_item_file_id_idx ON "purchaseItem" ("fileId");
+
+CREATE TABLE "purchasePayment" (
+    "purchaseId"        integer NOT NULL REFERENCES purchase ("purchaseId") ON DELETE CASCADE,
+    "sellerId"          integer NOT NULL,
+    "bankAccountName"   text    NOT NULL,
+    "bankAccountHolder" text    NOT NULL,
+    "bankAccountNumber" text    NOT NULL,
+    "totalPrice"        integer NOT NULL,
+    "fileId"            integer,
+    PRIMARY KEY ("purchaseId", "sellerId")
+);
+CREATE INDEX purchase_payment_file_id_idx ON "purchasePayment" ("fileId");
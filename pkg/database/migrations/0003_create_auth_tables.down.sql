@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "auditLog";
+DROP TABLE IF EXISTS "loginAttempt";
+DROP TABLE IF EXISTS "verificationCode";
+DROP TABLE IF EXISTS "passwordReset";
+DROP TABLE IF EXISTS "revokedToken";
+DROP TABLE IF EXISTS "refreshToken";
+DROP TABLE IF EXISTS "userSession";
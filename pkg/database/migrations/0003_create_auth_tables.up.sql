@@ -0,0 +1,58 @@
+CREATE TABLE "userSession" (
+    "sessionId" text PRIMARY KEY,
+    "userId"    integer     NOT NULL REFERENCES public.user ("userId") ON DELETE CASCADE,
+    "createdAt" timestamptz NOT NULL DEFAULT now(),
+    "revokedAt" timestamptz
+);
+CREATE INDEX user_session_user_id_idx ON "userSession" ("userId");
+
+CREATE TABLE "refreshToken" (
+    "tokenHash" text PRIMARY KEY,
+    "sessionId" text        NOT NULL REFERENCES "userSession" ("sessionId") ON DELETE CASCADE,
+    "expiresAt" timestamptz NOT NULL,
+    "usedAt"    timestamptz,
+    "createdAt" timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX refresh_token_session_id_idx ON "refreshToken" ("sessionId");
+
+CREATE TABLE "revokedToken" (
+    jti         text PRIMARY KEY,
+    "expiresAt" timestamptz NOT NULL
+);
+
+CREATE TABLE "passwordReset" (
+    "tokenHash" text PRIMARY KEY,
+    "userId"    integer     NOT NULL REFERENCES public.user ("userId") ON DELETE CASCADE,
+    "expiresAt" timestamptz NOT NULL,
+    "createdAt" timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX password_reset_user_id_idx ON "passwordReset" ("userId");
+
+CREATE TABLE "verificationCode" (
+    "userId"    integer     NOT NULL REFERENCES public.user ("userId") ON DELETE CASCADE,
+    channel     text        NOT NULL,
+    destination text        NOT NULL,
+    "codeHash"  text        NOT NULL,
+    attempts    integer     NOT NULL DEFAULT 0,
+    "expiresAt" timestamptz NOT NULL,
+    "createdAt" timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY ("userId", channel)
+);
+
+CREATE TABLE "loginAttempt" (
+    key            text PRIMARY KEY,
+    failures       integer     NOT NULL DEFAULT 0,
+    "lastFailedAt" timestamptz NOT NULL,
+    "lockedUntil"  timestamptz
+);
+
+-- "userId" pelaku atau akun yang terkait, tanpa foreign key supaya log tetap ada setelah akun dihapus
+CREATE TABLE "auditLog" (
+    id          bigserial PRIMARY KEY,
+    "userId"    integer,
+    event       text        NOT NULL,
+    ip          text        NOT NULL,
+    detail      jsonb       NOT NULL DEFAULT '{}',
+    "createdAt" timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX audit_log_user_id_idx ON "auditLog" ("userId", "createdAt");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS purchase_pending_expires_at_idx;
+ALTER TABLE purchase DROP COLUMN IF EXISTS "cancelledAt";
+ALTER TABLE purchase DROP COLUMN IF EXISTS "expiresAt";
@@ -0,0 +1,21 @@
+-- Stok dipesan saat checkout dan dilepas lagi kalau order dibatalkan atau tidak dibayar sampai "expiresAt"
+ALTER TABLE purchase ADD COLUMN "expiresAt" timestamptz;
+ALTER TABLE purchase ADD COLUMN "cancelledAt" timestamptz;
+
+-- Order pending lama diberi waktu sehari lagi untuk dibayar
+UPDATE purchase SET "expiresAt" = now() + interval '1 day' WHERE status = 'pending';
+UPDATE purchase SET "expiresAt" = "createdAt" WHERE "expiresAt" IS NULL;
+ALTER TABLE purchase ALTER COLUMN "expiresAt" SET NOT NULL;
+
+-- "sold" sekarang baru bertambah saat order dibayar, jadi qty order yang masih pending dikeluarkan
+UPDATE product p SET sold = p.sold - pending.qty
+FROM (
+    SELECT i."productId", SUM(i.qty) AS qty
+    FROM "purchaseItem" i
+    JOIN purchase pu ON pu."purchaseId" = i."purchaseId"
+    WHERE pu.status = 'pending'
+    GROUP BY i."productId"
+) pending
+WHERE p."productId" = pending."productId";
+
+CREATE INDEX purchase_pending_expires_at_idx ON purchase ("expiresAt") WHERE status = 'pending';
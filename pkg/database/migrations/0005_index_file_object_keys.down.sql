@@ -0,0 +1 @@
+DROP INDEX IF EXISTS file_object_keys_idx;
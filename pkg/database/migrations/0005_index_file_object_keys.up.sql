@@ -0,0 +1,2 @@
+-- Orphan sweeper mencocokkan satu batch key sekaligus dengan "objectKeys" && $1
+CREATE INDEX file_object_keys_idx ON file USING gin ("objectKeys");